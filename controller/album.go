@@ -7,6 +7,7 @@ import (
 	"rest/database"
 	"rest/middlewares"
 	"rest/models"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,33 +15,176 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 //connect to to the database and open an album collection
 var client *mongo.Client
 var albumsCollection *mongo.Collection
+var artistsCollection *mongo.Collection
+var coversBucket *gridfs.Bucket
+var previewsBucket *gridfs.Bucket
+var genresCollection *mongo.Collection
+var labelsCollection *mongo.Collection
+var pricesCollection *mongo.Collection
+var schedulesCollection *mongo.Collection
+var reservationsCollection *mongo.Collection
+var movementsCollection *mongo.Collection
+var cartsCollection *mongo.Collection
+var ordersCollection *mongo.Collection
+var promotionsCollection *mongo.Collection
+var redemptionsCollection *mongo.Collection
+var reviewsCollection *mongo.Collection
+var wishlistsCollection *mongo.Collection
+var notificationsCollection *mongo.Collection
+var locksCollection *mongo.Collection
 
 var validate *validator.Validate
 
 func init() {
 	client = database.DBinstance()
 	albumsCollection = database.OpenCollection(client, "albums")
+	database.CreateAlbumIndexes(albumsCollection)
+	artistsCollection = database.OpenCollection(client, "artists")
+	database.CreateArtistIndexes(artistsCollection)
+	genresCollection = database.OpenCollection(client, "genres")
+	database.CreateGenreIndexes(genresCollection)
+	labelsCollection = database.OpenCollection(client, "labels")
+	database.CreateLabelIndexes(labelsCollection)
+	pricesCollection = database.OpenCollection(client, "album_prices")
+	schedulesCollection = database.OpenCollection(client, "price_schedules")
+	database.CreatePriceIndexes(pricesCollection, schedulesCollection)
+	reservationsCollection = database.OpenCollection(client, "reservations")
+	movementsCollection = database.OpenCollection(client, "inventory_movements")
+	database.CreateInventoryIndexes(reservationsCollection, movementsCollection)
+	cartsCollection = database.OpenCollection(client, "carts")
+	database.CreateCartIndexes(cartsCollection)
+	ordersCollection = database.OpenCollection(client, "orders")
+	database.CreateOrderIndexes(ordersCollection)
+	promotionsCollection = database.OpenCollection(client, "promotions")
+	redemptionsCollection = database.OpenCollection(client, "promotion_redemptions")
+	database.CreatePromotionIndexes(promotionsCollection, redemptionsCollection)
+	reviewsCollection = database.OpenCollection(client, "reviews")
+	database.CreateReviewIndexes(reviewsCollection)
+	wishlistsCollection = database.OpenCollection(client, "wishlists")
+	notificationsCollection = database.OpenCollection(client, "notifications")
+	locksCollection = database.OpenCollection(client, "locks")
+	database.CreateWishlistIndexes(wishlistsCollection, notificationsCollection)
+	coversBucket = database.OpenBucket(client, "covers")
+	previewsBucket = database.OpenBucket(client, "previews")
+	database.CreatePreviewIndexes(previewsBucket)
 	validate = validator.New()
+	registerValidators(validate)
+	initPreviews()
+	initIdentity()
+	initExchangeRates()
+	initPriceScheduler()
+	initInventory()
+	initCarts()
+	initOrders()
+	initTaxAndShipping()
+
+	if buckets := middlewares.DotEnvVariable("PRICE_BUCKETS"); buckets != "" {
+		var err error
+		if defaultPriceBuckets, err = parsePriceBuckets(buckets); err != nil {
+			log.Fatal("PRICE_BUCKETS: ", err)
+		}
+	}
 }
 
 // GetAlbumByID godoc
 // @Summary      Get all albums
 // @Description  get albums
 // @Tags         albums
-// @Accept       json
-// @Produce      json
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        title      query     string  false  "Title contains"
+// @Param        artist     query     string  false  "Artist name"
+// @Param        min_price  query     number  false  "Minimum price"
+// @Param        max_price  query     number  false  "Maximum price"
+// @Param        genre      query     string  false  "Genre ID, albums of its sub-genres match too"
+// @Param        tag        query     string  false  "Comma separated tags the albums all carry"
+// @Param        label      query     string  false  "Label ID"
+// @Param        format     query     string  false  "Release format, one of cd, vinyl or digital"
+// @Param        country    query     string  false  "ISO 3166-1 alpha-2 country of release"
+// @Param        catalog_number  query  string  false  "Catalog number"
+// @Param        barcode    query     string  false  "UPC or EAN barcode"
+// @Param        released_from  query  string  false  "Released on or after, 2006-01-02"
+// @Param        released_to    query  string  false  "Released on or before, 2006-01-02"
+// @Param        low_stock      query  bool    false  "Only the albums whose available stock dropped to their low stock threshold"
+// @Param        sort       query     string  false  "Set to rating for the best rated albums first"
+// @Param        page       query     int     false  "Page number, starting at 1"
+// @Param        limit      query     int     false  "Albums per page, at most 100"
+// @Param        facets     query     string  false  "Facets to count, any of artist,price_bucket,year. Wraps the albums in a models.FacetedAlbums"
+// @Param        price_buckets  query  string  false  "Ascending price bucket boundaries, e.g. 0,10,20,50"
+// @Param        expand     query     string  false  "Set to artist to embed the artist of every album"
+// @Param        If-Modified-Since  header  string  false  "Answer with 304 when the albums did not change since"
 // @Success      200  {array}  	models.Album
+// @Success      304  "Not Modified"
 // @Failure      400  {object}  models.ErrorMessage
 // @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
 // @Failure      500  {object}  models.ErrorMessage
-// @Router       /albums [get]
+// @Router       /v1/albums [get]
 func GetAlbums(c *gin.Context) {
-	cursor, err := albumsCollection.Find(c, bson.M{})
+	if !negotiate(c) {
+		return
+	}
+
+	filter, err := albumFilter(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	facetNames, err := requestedFacets(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	buckets, err := priceBuckets(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	sort, err := albumSort(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if notModified(c, listModified(c)) {
+		return
+	}
+
+	opts := options.Find().SetSort(sort)
+
+	if paginated {
+		total, err := albumsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	cursor, err := albumsCollection.Find(c, filter, opts)
 
 	if err != nil {
 		log.Fatal(err)
@@ -52,22 +196,51 @@ func GetAlbums(c *gin.Context) {
 		log.Fatal(err)
 	}
 
-	c.JSON(http.StatusOK, albums)
+	if expandArtist(c) {
+		if err = expandArtists(c, albums); err != nil {
+			log.Println("artist expansion failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artists"})
+			return
+		}
+	}
+
+	if facetNames != nil {
+		facets, err := albumFacets(c, filter, facetNames, buckets)
+
+		if err != nil {
+			log.Println("album facets failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not count the facets"})
+			return
+		}
+
+		respond(c, http.StatusOK, models.FacetedAlbums{Albums: albums, Facets: facets})
+		return
+	}
+
+	respond(c, http.StatusOK, models.Albums(albums))
 }
 
 // GetAlbumByID godoc
 // @Summary      Get an album
 // @Description  get string by ID
 // @Tags         albums
-// @Accept       json
-// @Produce      json
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
 // @Param        id   path      string  true  "Album ID"
+// @Param        expand  query  string  false  "Set to artist to embed the artist of the album"
+// @Param        If-Modified-Since  header  string  false  "Answer with 304 when the album did not change since"
 // @Success      200  {object}  models.Album
+// @Success      304  "Not Modified"
 // @Failure      400  {object}  models.ErrorMessage
 // @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
 // @Failure      500  {object}  models.ErrorMessage
-// @Router       /albums/{id} [get]
+// @Router       /v1/albums/{id} [get]
 func GetAlbumByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
 	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
 
 	var album models.Album
@@ -75,27 +248,58 @@ func GetAlbumByID(c *gin.Context) {
 	err := albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	if notModified(c, album.Updated_at) {
 		return
 	}
 
-	c.JSON(http.StatusOK, album)
+	if expandArtist(c) {
+		albums := []models.Album{album}
+
+		if err = expandArtists(c, albums); err != nil {
+			log.Println("artist expansion failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artist"})
+			return
+		}
+
+		album = albums[0]
+	}
+
+	respond(c, http.StatusOK, album)
 }
 
 // PostAlbum godoc
 // @Summary      Add an album
-// @Description  add album by json
+// @Description  add album by json, an unknown artist name creates the artist
 // @Tags         albums
-// @Accept       json
-// @Produce      json
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
 // @Param        album   body      models.AddAlbum  true  "Add Album"
 // @Success      200	{object}  models.Album
 // @Failure      400	{object}  models.ErrorMessage
 // @Failure      404	{object}  models.ErrorMessage
+// @Failure      406	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
 // @Failure      500	{object}  models.ErrorMessage
 // @Security     bearer
-// @Router       /albums [post]
+// @Router       /v1/albums [post]
 func PostAlbum(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	// the request body may be sent in any of the negotiable formats
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
 	//this is used to determine how long the API call should last
 	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
 
@@ -104,20 +308,21 @@ func PostAlbum(c *gin.Context) {
 	token := c.GetHeader("Authorization")
 
 	if !middlewares.IsValidToken(token) {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
 		cancel()
 		return
 	}
 
-	// Call ShouldBindJSON to bind the received JSON to album.
-	if err := c.ShouldBindJSON(&album); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+	// Call ShouldBindWith to bind the received body to album.
+	if err := c.ShouldBindWith(&album, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
 		cancel()
 		return
 	}
 
-	if validationErr := validate.Struct(album); validationErr != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": validationErr.Error()})
+	prepareAlbum(&album)
+
+	if !linkAlbum(ctx, c, &album) {
 		cancel()
 		return
 	}
@@ -131,32 +336,52 @@ func PostAlbum(c *gin.Context) {
 
 	//insert the newly created object into mongodb
 	result, insertErr := albumsCollection.InsertOne(ctx, album)
+	if mongo.IsDuplicateKeyError(insertErr) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "sku already exists"})
+		cancel()
+		return
+	}
 	if insertErr != nil {
 		msg := "Album was not created"
-		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: msg})
 		cancel()
 		return
 	}
 	defer cancel()
 
 	//return the id of the created object
-	c.JSON(http.StatusOK, result)
+	respond(c, http.StatusOK, result)
 }
 
 // UpdateAlbum godoc
 // @Summary      Update an album
 // @Description  Update by json album
 // @Tags         albums
-// @Accept       json
-// @Produce      json
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
 // @Param        id       path      string	true  "Account ID"
 // @Param        album	body      models.AddAlbum  true  "Update Album"
 // @Success      200      {object}  models.SuccessMessage
 // @Failure      400      {object}  models.ErrorMessage
 // @Failure      404      {object}  models.ErrorMessage
+// @Failure      406      {object}  models.ErrorMessage
+// @Failure      415      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
 // @Failure      500      {object}  models.ErrorMessage
-// @Router       /albums/{id} [patch]
+// @Router       /v1/albums/{id} [patch]
 func UpdateAlbum(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	// the request body may be sent in any of the negotiable formats
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
 	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
 
 	var album models.Album
@@ -164,54 +389,185 @@ func UpdateAlbum(c *gin.Context) {
 	err := albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album)
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
 		return
 	}
 
-	// Call ShouldBindJSON to bind the received JSON to album.
-	if err = c.ShouldBindJSON(&album); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+	artistID, artist := album.ArtistID, album.Artist
+	price, currency := album.Price, album.Currency
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	// Call ShouldBindWith to bind the received body to album.
+	if err = c.ShouldBindWith(&album, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
 		return
 	}
 
-	if validationErr := validate.Struct(&album); validationErr != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": validationErr.Error()})
+	// a new artist name without a new artist_id moves the album to that artist
+	if album.ArtistID == artistID && album.Artist != artist {
+		album.ArtistID = primitive.NilObjectID
+	}
+
+	prepareAlbum(&album)
+
+	if !linkAlbum(c, c, &album) {
 		return
 	}
 
 	album.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
 
-	res, _ := albumsCollection.UpdateByID(c, id, bson.M{"$set": album})
+	res, err := albumsCollection.UpdateByID(c, id, bson.M{"$set": album})
 
-	if res.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "sku already exists"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "successfully updated the album"})
+	if err != nil || res.MatchedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	deleteOrphanPreviews(c, album)
+
+	album.ID = id
+	priceChanged(c, album, price, currency, models.PriceSourceUpdate)
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the album"})
 }
 
 // DeleteAlbumByID godoc
 // @Summary      Delete an albums
 // @Description  Delete by album ID
 // @Tags         albums
-// @Accept       json
-// @Produce      json
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
 // @Param        id   path      string  true  "Album ID"
 // @Success      200      {object}  models.SuccessMessage
 // @Failure      400      {object}  models.ErrorMessage
 // @Failure      404      {object}  models.ErrorMessage
+// @Failure      406      {object}  models.ErrorMessage
 // @Failure      500      {object}  models.ErrorMessage
-// @Router       /albums/{id} [delete]
+// @Router       /v1/albums/{id} [delete]
 func DeleteAlbumByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
 	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
 
-	res, _ := albumsCollection.DeleteOne(c, bson.M{"_id": id})
+	var album models.Album
+
+	opts := options.FindOneAndDelete().SetProjection(bson.M{"cover": 1})
+
+	if err := albumsCollection.FindOneAndDelete(c, bson.M{"_id": id}, opts).Decode(&album); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	albumsDeleted()
+	cleanUpDeletedAlbums(c, []models.Album{album})
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the album"})
+}
 
-	if res.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "album not found"})
+// cleanUpDeletedAlbums removes the covers, previews and reviews of albums
+// that are gone and takes them off the wishlists, both the single and the
+// bulk delete end here
+func cleanUpDeletedAlbums(ctx context.Context, albums []models.Album) {
+	if len(albums) == 0 {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "successfully deleted the album"})
+	ids := make([]primitive.ObjectID, len(albums))
+
+	for i, album := range albums {
+		deleteCoverImages(album.Cover)
+		ids[i] = album.ID
+	}
+
+	deletePreviews(ctx, bson.M{"metadata.album_id": bson.M{"$in": ids}})
+
+	if _, err := reviewsCollection.DeleteMany(ctx, bson.M{"album_id": bson.M{"$in": ids}}); err != nil {
+		log.Println("deleting the reviews failed:", err)
+	}
+
+	_, err := wishlistsCollection.UpdateMany(ctx, bson.M{"items.album_id": bson.M{"$in": ids}},
+		bson.M{"$pull": bson.M{"items": bson.M{"album_id": bson.M{"$in": ids}}}})
+
+	if err != nil {
+		log.Println("removing the albums from the wishlists failed:", err)
+	}
+}
+
+// prepareAlbum brings the fields clients may send in several forms into the
+// stored one
+func prepareAlbum(album *models.Album) {
+	prepareTracks(album)
+
+	album.Currency = strings.ToUpper(strings.TrimSpace(album.Currency))
+	if album.Currency == "" {
+		album.Currency = defaultCurrency
+	}
+
+	prepareVariants(album)
+	album.Tags = normalizeTags(album.Tags)
+
+	var genres []primitive.ObjectID
+	for _, id := range album.Genres {
+		if !containsID(genres, id) {
+			genres = append(genres, id)
+		}
+	}
+	album.Genres = genres
+
+	album.Barcode = normalizeBarcode(album.Barcode)
+	album.CatalogNumber = strings.TrimSpace(album.CatalogNumber)
+	album.Format = strings.ToLower(strings.TrimSpace(album.Format))
+	album.Country = strings.ToUpper(strings.TrimSpace(album.Country))
+
+	// the stock only changes through the inventory endpoints, the rating
+	// through the reviews
+	album.Inventory = nil
+	album.Rating = nil
+}
+
+// linkAlbum validates the album and links it to its artist, responding with
+// the error when that fails
+func linkAlbum(ctx context.Context, c *gin.Context, album *models.Album) bool {
+	err := linkArtistByID(ctx, album)
+
+	if err == nil {
+		err = checkGenres(ctx, album.Genres)
+	}
+
+	if err == nil {
+		err = checkLabel(ctx, album.LabelID)
+	}
+
+	if err == errArtistNotFound || err == errGenreNotFound || err == errLabelNotFound {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: err.Error()})
+		return false
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artist"})
+		return false
+	}
+
+	if validationErr := validate.Struct(album); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return false
+	}
+
+	if err = linkArtistByName(ctx, album); err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not create the artist"})
+		return false
+	}
+
+	setSearchGrams(album)
+
+	return true
 }
@@ -260,3 +260,76 @@ func TestDeleteAlbumRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestAlbumContentNegotiation(t *testing.T) {
+
+	test_cases := []struct {
+		name        string
+		method      string
+		path        string
+		body        []byte
+		accept      string
+		contentType string
+		response    string
+		status      int
+	}{
+		{
+			name:     "get an album by wrong id as xml",
+			method:   "GET",
+			path:     "/albums/1",
+			accept:   "application/xml",
+			response: "<ErrorMessage><error>album not found</error></ErrorMessage>",
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "get an album by wrong id as yaml",
+			method:   "GET",
+			path:     "/albums/1",
+			accept:   "application/x-yaml",
+			response: "error: album not found\n",
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "try to get albums in an unsupported format",
+			method:   "GET",
+			path:     "/albums",
+			accept:   "text/csv",
+			response: `{"error":"not acceptable"}`,
+			status:   http.StatusNotAcceptable,
+		},
+		{
+			name:        "try to create an album from an unsupported format",
+			method:      "POST",
+			path:        "/albums",
+			body:        []byte("title=New album"),
+			contentType: "text/plain",
+			response:    `{"error":"unsupported media type"}`,
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "try to create an album from invalid xml",
+			method:      "POST",
+			path:        "/albums",
+			body:        []byte("<AddAlbum><title>New album"),
+			contentType: "application/xml",
+			accept:      "application/xml",
+			response:    "<map><message>invalid data</message></map>",
+			status:      http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, apiprefix+tc.path, bytes.NewBuffer(tc.body))
+			req.Header.Add("Authorization", "owais")
+			req.Header.Add("Accept", tc.accept)
+			req.Header.Add("Content-Type", tc.contentType)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.response, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"rest/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAlbumsV2 godoc
+// @Summary      Get all albums
+// @Description  get albums with their prices as exact money, optionally converted to another currency
+// @Tags         albums v2
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        currency   query     string  false  "ISO 4217 currency to convert the prices to"
+// @Param        title      query     string  false  "Title contains"
+// @Param        artist     query     string  false  "Artist name"
+// @Param        min_price  query     number  false  "Minimum price, in the currency of each album"
+// @Param        max_price  query     number  false  "Maximum price, in the currency of each album"
+// @Param        genre      query     string  false  "Genre ID, albums of its sub-genres match too"
+// @Param        tag        query     string  false  "Comma separated tags the albums all carry"
+// @Param        label      query     string  false  "Label ID"
+// @Param        page       query     int     false  "Page number, starting at 1"
+// @Param        limit      query     int     false  "Albums per page, at most 100"
+// @Param        expand     query     string  false  "Set to artist to embed the artist of every album"
+// @Param        If-Modified-Since  header  string  false  "Answer with 304 when the albums did not change since"
+// @Success      200  {array}   models.AlbumV2
+// @Success      304  "Not Modified"
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v2/albums [get]
+func GetAlbumsV2(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return
+	}
+
+	filter, err := albumFilter(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if notModified(c, pricesModified(listModified(c), currency)) {
+		return
+	}
+
+	opts := options.Find()
+
+	if paginated {
+		total, err := albumsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("v2 albums failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSort(bson.M{"_id": 1}).SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	cursor, err := albumsCollection.Find(c, filter, opts)
+
+	if err != nil {
+		log.Println("v2 albums failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+		return
+	}
+
+	var albums []models.Album
+
+	if err = cursor.All(c, &albums); err != nil {
+		log.Println("v2 albums failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+		return
+	}
+
+	if expandArtist(c) {
+		if err = expandArtists(c, albums); err != nil {
+			log.Println("artist expansion failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artists"})
+			return
+		}
+	}
+
+	list := make(models.AlbumsV2, 0, len(albums))
+
+	for _, album := range albums {
+		v2, err := albumV2(album, currency)
+
+		if err != nil {
+			respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+			return
+		}
+
+		list = append(list, v2)
+	}
+
+	respond(c, http.StatusOK, list)
+}
+
+// GetAlbumByIDV2 godoc
+// @Summary      Get an album
+// @Description  get an album with its prices as exact money, optionally converted to another currency
+// @Tags         albums v2
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id        path      string  true   "Album ID"
+// @Param        currency  query     string  false  "ISO 4217 currency to convert the prices to"
+// @Param        expand    query     string  false  "Set to artist to embed the artist of the album"
+// @Param        If-Modified-Since  header  string  false  "Answer with 304 when the album did not change since"
+// @Success      200  {object}  models.AlbumV2
+// @Success      304  "Not Modified"
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v2/albums/{id} [get]
+func GetAlbumByIDV2(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var album models.Album
+
+	err := albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	if notModified(c, pricesModified(album.Updated_at, currency)) {
+		return
+	}
+
+	if expandArtist(c) {
+		albums := []models.Album{album}
+
+		if err = expandArtists(c, albums); err != nil {
+			log.Println("artist expansion failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artist"})
+			return
+		}
+
+		album = albums[0]
+	}
+
+	v2, err := albumV2(album, currency)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	respond(c, http.StatusOK, v2)
+}
+
+// requestedCurrency reads ?currency, empty when the albums keep their own
+func requestedCurrency(c *gin.Context) (string, bool) {
+	currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+
+	if currency == "" {
+		return "", true
+	}
+
+	if validate.Var(currency, "iso4217") != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid currency"})
+		return "", false
+	}
+
+	return currency, true
+}
+
+// pricesModified takes the exchange rates into account when the prices of a
+// response are converted with them
+func pricesModified(modified time.Time, currency string) time.Time {
+	if rates := ratesModified(); currency != "" && rates.After(modified) {
+		return rates
+	}
+
+	return modified
+}
+
+// albumV2 builds the v2 representation of the album, with its prices in
+// currency, or in its own currency when currency is empty
+func albumV2(album models.Album, currency string) (models.AlbumV2, error) {
+	if currency == "" {
+		currency = album.Currency
+
+		if currency == "" {
+			currency = defaultCurrency
+		}
+	}
+
+	v2 := models.AlbumV2{
+		ID:            album.ID,
+		Title:         album.Title,
+		Artist:        album.Artist,
+		ArtistID:      album.ArtistID,
+		Variants:      []models.VariantV2{},
+		Tracks:        album.Tracks,
+		Duration:      album.Duration,
+		Genres:        album.Genres,
+		Tags:          album.Tags,
+		ReleaseDate:   album.ReleaseDate,
+		LabelID:       album.LabelID,
+		CatalogNumber: album.CatalogNumber,
+		Barcode:       album.Barcode,
+		Format:        album.Format,
+		Country:       album.Country,
+		Weight:        album.Weight,
+		Inventory:     album.Inventory,
+		Rating:        album.Rating,
+		Cover:         album.Cover,
+		Created_at:    album.Created_at,
+		Updated_at:    album.Updated_at,
+		ArtistDetails: album.ArtistDetails,
+	}
+
+	var err error
+
+	if v2.Price, err = convertPrice(album.Price, album.Currency, currency); err != nil {
+		return v2, noExchangeRate(album.Currency, currency)
+	}
+
+	for _, variant := range album.Variants {
+		price, err := convertPrice(variant.Price, variant.Currency, currency)
+
+		if err != nil {
+			return v2, noExchangeRate(variant.Currency, currency)
+		}
+
+		v2.Variants = append(v2.Variants, models.VariantV2{
+			ID:     variant.ID,
+			SKU:    variant.SKU,
+			Format: variant.Format,
+			Price:  price,
+			Stock:  variant.Stock,
+		})
+	}
+
+	if album.PriceRange != nil {
+		min, err := convertPrice(album.PriceRange.Min, album.PriceRange.Currency, currency)
+
+		if err != nil {
+			return v2, noExchangeRate(album.PriceRange.Currency, currency)
+		}
+
+		max, _ := convertPrice(album.PriceRange.Max, album.PriceRange.Currency, currency)
+		v2.PriceRange = &models.MoneyRange{Min: min, Max: max}
+	}
+
+	return v2, nil
+}
+
+func noExchangeRate(from, to string) error {
+	if from == "" {
+		from = defaultCurrency
+	}
+
+	return errors.New("no exchange rate from " + from + " to " + to)
+}
@@ -0,0 +1,118 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumV2Routes(t *testing.T) {
+
+	w := serve("PUT", "/api/v2/exchange-rates", `{"base": "eur", "rates": {"USD": "1.0850", "JPY": "162.3"}}`, admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve("GET", "/api/v2/exchange-rates", "", map[string]string{"Accept": "application/xml"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<rate currency="USD">1.0850</rate>`)
+
+	w = request("POST", "/albums", `{"title": "Money album", "artist": "Me Owais", "price": 9.99, "currency": "eur"}`, admin)
+
+	var postRes PostResponse
+	json.Unmarshal(w.Body.Bytes(), &postRes)
+
+	w = request("POST", "/albums", `{"title": "Odd priced album", "artist": "Me Owais", "price": 4.995, "currency": "eur"}`, admin)
+
+	var oddRes PostResponse
+	json.Unmarshal(w.Body.Bytes(), &oddRes)
+
+	test_cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		price  string
+		status int
+	}{
+		{
+			name:   "get an album in its own currency",
+			method: "GET",
+			path:   "/albums/" + postRes.InsertedID,
+			price:  `{"amount":"9.99","currency":"EUR"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "get an album converted to another currency",
+			method: "GET",
+			path:   "/albums/" + postRes.InsertedID + "?currency=usd",
+			price:  `{"amount":"10.84","currency":"USD"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "get an album in a currency without cents",
+			method: "GET",
+			path:   "/albums/" + postRes.InsertedID + "?currency=JPY",
+			price:  `{"amount":"1621","currency":"JPY"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "get an album rounded to the cent in its own currency",
+			method: "GET",
+			path:   "/albums/" + oddRes.InsertedID,
+			price:  `{"amount":"5.00","currency":"EUR"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "get an album rounded to the cent when asking for its own currency",
+			method: "GET",
+			path:   "/albums/" + oddRes.InsertedID + "?currency=eur",
+			price:  `{"amount":"5.00","currency":"EUR"}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "try to convert to a currency without a rate",
+			method: "GET",
+			path:   "/albums/" + postRes.InsertedID + "?currency=GBP",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "try to convert to an unknown currency",
+			method: "GET",
+			path:   "/albums?currency=XYZ",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "try to load a negative rate",
+			method: "PUT",
+			path:   "/exchange-rates",
+			body:   `{"base": "EUR", "rates": {"USD": "-1"}}`,
+			status: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(tc.method, "/api/v2"+tc.path, tc.body, admin)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.price != "" {
+				var album struct {
+					Price json.RawMessage `json:"price"`
+				}
+				json.Unmarshal(w.Body.Bytes(), &album)
+
+				assert.JSONEq(t, tc.price, string(album.Price))
+			}
+		})
+	}
+
+	w = request("DELETE", "/albums/"+postRes.InsertedID, "", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = request("DELETE", "/albums/"+oddRes.InsertedID, "", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,528 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"rest/database"
+	"rest/middlewares"
+	"rest/models"
+	"rest/search"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errArtistNotFound = errors.New("artist not found")
+
+// GetArtists godoc
+// @Summary      Get all artists
+// @Description  get artists sorted by name
+// @Tags         artists
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        name   query     string  false  "Name contains"
+// @Param        page   query     int     false  "Page number, starting at 1"
+// @Param        limit  query     int     false  "Artists per page, at most 100"
+// @Success      200  {array}   models.Artist
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/artists [get]
+func GetArtists(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	filter := bson.M{}
+
+	if name := c.Query("name"); name != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(name), "$options": "i"}
+	}
+
+	opts := options.Find().SetSort(bson.M{"name": 1}).SetCollation(database.NameCollation)
+
+	if paginated {
+		total, err := artistsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("artists failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artists"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	cursor, err := artistsCollection.Find(c, filter, opts)
+
+	if err != nil {
+		log.Println("artists failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artists"})
+		return
+	}
+
+	artists := []models.Artist{}
+
+	if err = cursor.All(c, &artists); err != nil {
+		log.Println("artists failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the artists"})
+		return
+	}
+
+	respond(c, http.StatusOK, artists)
+}
+
+// GetArtistByID godoc
+// @Summary      Get an artist
+// @Description  get artist by ID
+// @Tags         artists
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Artist ID"
+// @Success      200  {object}  models.Artist
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/artists/{id} [get]
+func GetArtistByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var artist models.Artist
+
+	err := artistsCollection.FindOne(c, bson.M{"_id": id}).Decode(&artist)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "artist not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, artist)
+}
+
+// PostArtist godoc
+// @Summary      Add an artist
+// @Description  add artist by json, names are unique regardless of case
+// @Tags         artists
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        artist  body      models.AddArtist  true  "Add Artist"
+// @Success      200	{object}  models.Artist
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/artists [post]
+func PostArtist(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var artist models.Artist
+
+	if err := c.ShouldBindWith(&artist, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	artist.Name = strings.TrimSpace(artist.Name)
+
+	if validationErr := validate.Struct(artist); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	artist.ID = primitive.NewObjectID()
+	artist.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	artist.Updated_at = artist.Created_at
+
+	_, err := artistsCollection.InsertOne(c, artist)
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "artist already exists"})
+		return
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "Artist was not created"})
+		return
+	}
+
+	respond(c, http.StatusOK, artist)
+}
+
+// UpdateArtist godoc
+// @Summary      Update an artist
+// @Description  rename an artist, the albums credited to it follow
+// @Tags         artists
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id      path      string            true  "Artist ID"
+// @Param        artist  body      models.AddArtist  true  "Update Artist"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Failure      415      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
+// @Router       /v1/artists/{id} [patch]
+func UpdateArtist(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var artist models.Artist
+
+	err := artistsCollection.FindOne(c, bson.M{"_id": id}).Decode(&artist)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "artist not found"})
+		return
+	}
+
+	if err = c.ShouldBindWith(&artist, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	artist.ID = id
+	artist.Name = strings.TrimSpace(artist.Name)
+
+	if validationErr := validate.Struct(&artist); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	artist.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	res, err := artistsCollection.UpdateByID(c, id, bson.M{"$set": artist})
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "artist already exists"})
+		return
+	}
+
+	if err != nil || res.MatchedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "artist not found"})
+		return
+	}
+
+	// albums keep a copy of the name for searching and filtering
+	_, err = albumsCollection.UpdateMany(c,
+		bson.M{"artist_id": id},
+		bson.M{"$set": bson.M{"artist": artist.Name, "artist_grams": search.Grams(artist.Name), "updated_at": artist.Updated_at}})
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not rename the artist of its albums"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the artist"})
+}
+
+// DeleteArtistByID godoc
+// @Summary      Delete an artist
+// @Description  delete an artist that has no albums
+// @Tags         artists
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Artist ID"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Router       /v1/artists/{id} [delete]
+func DeleteArtistByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	albums, err := albumsCollection.CountDocuments(c, bson.M{"artist_id": id})
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not delete the artist"})
+		return
+	}
+
+	if albums > 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "artist still has albums"})
+		return
+	}
+
+	res, _ := artistsCollection.DeleteOne(c, bson.M{"_id": id})
+
+	if res.DeletedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "artist not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the artist"})
+}
+
+// GetArtistAlbums godoc
+// @Summary      Get the albums of an artist
+// @Description  get albums credited to the artist
+// @Tags         artists
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string  true   "Artist ID"
+// @Param        page   query     int     false  "Page number, starting at 1"
+// @Param        limit  query     int     false  "Albums per page, at most 100"
+// @Success      200  {array}   models.Album
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/artists/{id}/albums [get]
+func GetArtistAlbums(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if n, _ := artistsCollection.CountDocuments(c, bson.M{"_id": id}); n == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "artist not found"})
+		return
+	}
+
+	filter := bson.M{"artist_id": id}
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+
+	if paginated {
+		total, err := albumsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("artist albums failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	cursor, err := albumsCollection.Find(c, filter, opts)
+
+	if err != nil {
+		log.Println("artist albums failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+		return
+	}
+
+	var albums []models.Album
+
+	if err = cursor.All(c, &albums); err != nil {
+		log.Println("artist albums failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.Albums(albums))
+}
+
+// artistByName returns the artist with this name, ignoring case, and creates
+// it when there is none yet
+func artistByName(ctx context.Context, name string) (models.Artist, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return models.Artist{}, errors.New("artist name is empty")
+	}
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	var artist models.Artist
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After).
+		SetCollation(database.NameCollation)
+
+	// the name is taken from the filter when the artist is inserted
+	update := bson.M{"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "created_at": now, "updated_at": now}}
+
+	err := artistsCollection.FindOneAndUpdate(ctx, bson.M{"name": name}, update, opts).Decode(&artist)
+
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent request created it first
+		err = artistsCollection.FindOne(ctx, bson.M{"name": name}, options.FindOne().SetCollation(database.NameCollation)).Decode(&artist)
+	}
+
+	return artist, err
+}
+
+// linkArtistByID copies the name of the artist referenced by artist_id into
+// the album. It does nothing when the album has no artist_id.
+func linkArtistByID(ctx context.Context, album *models.Album) error {
+	if album.ArtistID.IsZero() {
+		return nil
+	}
+
+	var artist models.Artist
+
+	err := artistsCollection.FindOne(ctx, bson.M{"_id": album.ArtistID}).Decode(&artist)
+
+	if err == mongo.ErrNoDocuments {
+		return errArtistNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	album.Artist = artist.Name
+	return nil
+}
+
+// linkArtistByName links an album that only names its artist to the artist
+// document, creating the artist on first use
+func linkArtistByName(ctx context.Context, album *models.Album) error {
+	if !album.ArtistID.IsZero() {
+		return nil
+	}
+
+	artist, err := artistByName(ctx, album.Artist)
+
+	if err != nil {
+		return err
+	}
+
+	album.ArtistID = artist.ID
+	album.Artist = artist.Name
+	return nil
+}
+
+func expandArtist(c *gin.Context) bool {
+	for _, field := range strings.Split(c.Query("expand"), ",") {
+		if strings.TrimSpace(field) == "artist" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandArtists fills in the artist details of the albums
+func expandArtists(ctx context.Context, albums []models.Album) error {
+	var ids []primitive.ObjectID
+
+	for _, album := range albums {
+		if !album.ArtistID.IsZero() {
+			ids = append(ids, album.ArtistID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cursor, err := artistsCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+
+	if err != nil {
+		return err
+	}
+
+	var artists []models.Artist
+
+	if err = cursor.All(ctx, &artists); err != nil {
+		return err
+	}
+
+	byID := make(map[primitive.ObjectID]*models.Artist, len(artists))
+	for i := range artists {
+		byID[artists[i].ID] = &artists[i]
+	}
+
+	for i := range albums {
+		albums[i].ArtistDetails = byID[albums[i].ArtistID]
+	}
+
+	return nil
+}
+
+// MigrateArtists links albums that only carry an artist name to artist
+// documents, creating the artists as needed. It is safe to run repeatedly.
+func MigrateArtists(ctx context.Context) (*models.ArtistMigration, error) {
+	unlinked := bson.M{"artist_id": bson.M{"$exists": false}}
+
+	names, err := albumsCollection.Distinct(ctx, "artist", unlinked)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ArtistMigration{}
+
+	for _, value := range names {
+		name, ok := value.(string)
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		artist, err := artistByName(ctx, name)
+
+		if err != nil {
+			return report, err
+		}
+
+		res, err := albumsCollection.UpdateMany(ctx,
+			bson.M{"artist": name, "artist_id": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"artist_id": artist.ID, "artist": artist.Name, "artist_grams": search.Grams(artist.Name)}})
+
+		if err != nil {
+			return report, err
+		}
+
+		report.Artists++
+		report.Albums += int(res.ModifiedCount)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,143 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtistRoutes(t *testing.T) {
+
+	var artistID, albumID string
+
+	// posting an album with a new artist name creates the artist
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", apiprefix+"/albums", bytes.NewBufferString(`{
+		"title": "Artist album",
+		"artist": "Artist Test Band",
+		"price": 10
+	}`))
+	req.Header.Add("Authorization", "owais")
+	router.ServeHTTP(w, req)
+
+	var postRes PostResponse
+	json.Unmarshal(w.Body.Bytes(), &postRes)
+	albumID = postRes.InsertedID
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", apiprefix+"/albums/"+albumID+"?expand=artist", nil)
+	router.ServeHTTP(w, req)
+
+	var album models.Album
+	json.Unmarshal(w.Body.Bytes(), &album)
+
+	if assert.NotNil(t, album.ArtistDetails) {
+		assert.Equal(t, "Artist Test Band", album.ArtistDetails.Name)
+		artistID = album.ArtistID.Hex()
+	}
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		response string
+		status   int
+	}{
+		{
+			name:   "get an artist by id",
+			method: "GET",
+			path:   "/artists/" + artistID,
+			status: http.StatusOK,
+		},
+		{
+			name:     "get an artist by wrong id",
+			method:   "GET",
+			path:     "/artists/1",
+			response: `{"error":"artist not found"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "try to create an artist that exists in another case",
+			method:   "POST",
+			path:     "/artists",
+			body:     `{"name": "artist test band"}`,
+			response: `{"error":"artist already exists"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "try to create an album of an unknown artist id",
+			method:   "POST",
+			path:     "/albums",
+			body:     `{"title": "Lost album", "artist_id": "000000000000000000000001", "price": 10}`,
+			response: `{"error":"artist not found"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "rename an artist",
+			method:   "PATCH",
+			path:     "/artists/" + artistID,
+			body:     `{"name": "Artist Test Band Renamed"}`,
+			response: `{"message":"successfully updated the artist"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:   "get the albums of an artist",
+			method: "GET",
+			path:   "/artists/" + artistID + "/albums",
+			status: http.StatusOK,
+		},
+		{
+			name:     "try to delete an artist that has albums",
+			method:   "DELETE",
+			path:     "/artists/" + artistID,
+			response: `{"error":"artist still has albums"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "delete the album of the artist",
+			method:   "DELETE",
+			path:     "/albums/" + albumID,
+			response: `{"message":"successfully deleted the album"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "delete an artist",
+			method:   "DELETE",
+			path:     "/artists/" + artistID,
+			response: `{"message":"successfully deleted the artist"}`,
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, apiprefix+tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Add("Authorization", "owais")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+
+			if tc.name == "get the albums of an artist" {
+				var albums []models.Album
+				json.Unmarshal(w.Body.Bytes(), &albums)
+
+				if assert.Len(t, albums, 1) {
+					assert.Equal(t, "Artist Test Band Renamed", albums[0].Artist)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// upper bound of items accepted by a single batch call
+const maxBatchSize = 1000
+
+// BatchCreateAlbums godoc
+// @Summary      Add albums in bulk
+// @Description  add up to 1000 albums at once, reporting a status per item
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        albums   body      models.BatchCreateAlbums  true  "Add Albums"
+// @Success      200	{object}  models.BatchResponse
+// @Failure      422	{object}  models.BatchResponse
+// @Failure      500	{object}  models.BatchResponse
+// @Security     bearer
+// @Router       /v1/albums:batchCreate [post]
+func BatchCreateAlbums(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var req models.BatchCreateAlbums
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if !validBatchSize(c, len(req.Albums)) {
+		return
+	}
+
+	results := make([]models.BatchItemResult, len(req.Albums))
+	var writes []mongo.WriteModel
+	var items []int
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	var err error
+
+	for i, add := range req.Albums {
+		results[i].Index = i
+
+		album := models.Album{
+			ID:            primitive.NewObjectID(),
+			Title:         add.Title,
+			Artist:        add.Artist,
+			Price:         models.Amount(add.Price),
+			Currency:      add.Currency,
+			Tags:          add.Tags,
+			ReleaseDate:   add.ReleaseDate,
+			CatalogNumber: add.CatalogNumber,
+			Barcode:       add.Barcode,
+			Format:        add.Format,
+			Country:       add.Country,
+			Weight:        add.Weight,
+			Created_at:    now,
+			Updated_at:    now,
+		}
+
+		for _, track := range add.Tracks {
+			album.Tracks = append(album.Tracks, newTrack(track))
+		}
+
+		if album.Genres, err = parseObjectIDs(add.Genres); err != nil {
+			results[i].Status = models.BatchStatusInvalid
+			results[i].Error = "invalid genre id"
+			continue
+		}
+
+		if add.ArtistID != "" {
+			if album.ArtistID, err = primitive.ObjectIDFromHex(add.ArtistID); err != nil {
+				results[i].Status = models.BatchStatusInvalid
+				results[i].Error = "invalid artist_id"
+				continue
+			}
+		}
+
+		if add.LabelID != "" {
+			if album.LabelID, err = primitive.ObjectIDFromHex(add.LabelID); err != nil {
+				results[i].Status = models.BatchStatusInvalid
+				results[i].Error = "invalid label_id"
+				continue
+			}
+		}
+
+		prepareAlbum(&album)
+
+		if !linkBatchAlbum(ctx, &results[i], &album) {
+			continue
+		}
+
+		results[i].ID = album.ID.Hex()
+		results[i].Status = models.BatchStatusCreated
+		writes = append(writes, mongo.NewInsertOneModel().SetDocument(album))
+		items = append(items, i)
+	}
+
+	runBatch(ctx, c, results, writes, items, req.Atomic)
+}
+
+// BatchUpdateAlbums godoc
+// @Summary      Update albums in bulk
+// @Description  update up to 1000 albums at once, every item needs the "_id" of the album it updates
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        albums   body      models.BatchUpdateAlbums  true  "Update Albums"
+// @Success      200	{object}  models.BatchResponse
+// @Failure      422	{object}  models.BatchResponse
+// @Failure      500	{object}  models.BatchResponse
+// @Security     bearer
+// @Router       /v1/albums:batchUpdate [patch]
+func BatchUpdateAlbums(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var req models.BatchUpdateAlbums
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if !validBatchSize(c, len(req.Albums)) {
+		return
+	}
+
+	results := make([]models.BatchItemResult, len(req.Albums))
+	ids := make([]primitive.ObjectID, len(req.Albums))
+
+	var ref struct {
+		ID string `json:"_id"`
+	}
+
+	for i, raw := range req.Albums {
+		results[i].Index = i
+		ref.ID = ""
+
+		if err := json.Unmarshal(raw, &ref); err != nil {
+			results[i].Status = models.BatchStatusInvalid
+			results[i].Error = "invalid data"
+			continue
+		}
+
+		results[i].ID = ref.ID
+		ids[i], _ = primitive.ObjectIDFromHex(ref.ID)
+	}
+
+	markInvalidIDs(results, ids)
+
+	existing, err := findAlbums(ctx, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load albums"})
+		return
+	}
+
+	var writes []mongo.WriteModel
+	var items []int
+
+	// the albums each item wrote, and their price and currency before it
+	updated := make([]models.Album, len(req.Albums))
+	prices := make([]models.Amount, len(req.Albums))
+	currencies := make([]string, len(req.Albums))
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	for i, raw := range req.Albums {
+		if results[i].Status != "" {
+			continue
+		}
+
+		album, ok := existing[ids[i]]
+		if !ok {
+			results[i].Status = models.BatchStatusNotFound
+			results[i].Error = "album not found"
+			continue
+		}
+
+		artistID, artist := album.ArtistID, album.Artist
+		prices[i], currencies[i] = album.Price, album.Currency
+
+		if err := json.Unmarshal(raw, &album); err != nil {
+			results[i].Status = models.BatchStatusInvalid
+			results[i].Error = "invalid data"
+			continue
+		}
+
+		if album.ArtistID == artistID && album.Artist != artist {
+			album.ArtistID = primitive.NilObjectID
+		}
+
+		prepareAlbum(&album)
+
+		if !linkBatchAlbum(ctx, &results[i], &album) {
+			continue
+		}
+
+		album.ID = ids[i]
+		album.Updated_at = now
+
+		results[i].Status = models.BatchStatusUpdated
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": ids[i]}).
+			SetUpdate(bson.M{"$set": album}))
+		items = append(items, i)
+		existing[ids[i]] = album
+		updated[i] = album
+	}
+
+	runBatch(ctx, c, results, writes, items, req.Atomic)
+
+	for _, i := range items {
+		if results[i].Status == models.BatchStatusUpdated {
+			deleteOrphanPreviews(ctx, existing[ids[i]])
+			priceChanged(ctx, updated[i], prices[i], currencies[i], models.PriceSourceBatch)
+		}
+	}
+}
+
+// BatchDeleteAlbums godoc
+// @Summary      Delete albums in bulk
+// @Description  delete up to 1000 albums at once by album ID
+// @Tags         albums
+// @Accept       json
+// @Produce      json
+// @Param        ids   body      models.BatchDeleteAlbums  true  "Album IDs"
+// @Success      200	{object}  models.BatchResponse
+// @Failure      422	{object}  models.BatchResponse
+// @Failure      500	{object}  models.BatchResponse
+// @Security     bearer
+// @Router       /v1/albums:batchDelete [post]
+func BatchDeleteAlbums(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var req models.BatchDeleteAlbums
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if !validBatchSize(c, len(req.IDs)) {
+		return
+	}
+
+	results := make([]models.BatchItemResult, len(req.IDs))
+	ids := make([]primitive.ObjectID, len(req.IDs))
+
+	for i, hex := range req.IDs {
+		results[i].Index = i
+		results[i].ID = hex
+		ids[i], _ = primitive.ObjectIDFromHex(hex)
+	}
+
+	markInvalidIDs(results, ids)
+
+	existing, err := findAlbums(ctx, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not load albums"})
+		return
+	}
+
+	var writes []mongo.WriteModel
+	var items []int
+
+	for i := range req.IDs {
+		if results[i].Status != "" {
+			continue
+		}
+
+		if _, ok := existing[ids[i]]; !ok {
+			results[i].Status = models.BatchStatusNotFound
+			results[i].Error = "album not found"
+			continue
+		}
+
+		results[i].Status = models.BatchStatusDeleted
+		writes = append(writes, mongo.NewDeleteOneModel().SetFilter(bson.M{"_id": ids[i]}))
+		items = append(items, i)
+	}
+
+	runBatch(ctx, c, results, writes, items, req.Atomic)
+
+	if len(writes) > 0 {
+		albumsDeleted()
+	}
+
+	var deleted []models.Album
+
+	for i := range req.IDs {
+		if results[i].Status == models.BatchStatusDeleted {
+			deleted = append(deleted, existing[ids[i]])
+		}
+	}
+
+	cleanUpDeletedAlbums(ctx, deleted)
+}
+
+func validBatchSize(c *gin.Context, n int) bool {
+	if n == 0 || n > maxBatchSize {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "batch must contain between 1 and 1000 items"})
+		return false
+	}
+
+	return true
+}
+
+// markInvalidIDs flags items whose id is malformed or repeated within the batch
+func markInvalidIDs(results []models.BatchItemResult, ids []primitive.ObjectID) {
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+
+	for i, id := range ids {
+		if results[i].Status != "" {
+			continue
+		}
+
+		switch {
+		case id.IsZero():
+			results[i].Status = models.BatchStatusInvalid
+			results[i].Error = "invalid id"
+		case seen[id]:
+			results[i].Status = models.BatchStatusInvalid
+			results[i].Error = "duplicate id in batch"
+		default:
+			seen[id] = true
+		}
+	}
+}
+
+// linkBatchAlbum validates a batch item and links it to its artist, the
+// batch counterpart of linkAlbum
+func linkBatchAlbum(ctx context.Context, result *models.BatchItemResult, album *models.Album) bool {
+	err := linkArtistByID(ctx, album)
+
+	if err == nil {
+		err = checkGenres(ctx, album.Genres)
+	}
+
+	if err == nil {
+		err = checkLabel(ctx, album.LabelID)
+	}
+
+	if err == nil {
+		if validationErr := validate.Struct(album); validationErr != nil {
+			result.Status = models.BatchStatusInvalid
+			result.Error = validationErr.Error()
+			return false
+		}
+
+		err = linkArtistByName(ctx, album)
+	}
+
+	if err == nil {
+		setSearchGrams(album)
+	}
+
+	switch {
+	case err == errArtistNotFound, err == errGenreNotFound, err == errLabelNotFound:
+		result.Status = models.BatchStatusInvalid
+		result.Error = err.Error()
+	case err != nil:
+		result.Status = models.BatchStatusFailed
+		result.Error = err.Error()
+	}
+
+	return err == nil
+}
+
+// findAlbums loads the albums with the given ids, keyed by id
+func findAlbums(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Album, error) {
+	cursor, err := albumsCollection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	var albums []models.Album
+
+	if err = cursor.All(ctx, &albums); err != nil {
+		return nil, err
+	}
+
+	found := make(map[primitive.ObjectID]models.Album, len(albums))
+	for _, album := range albums {
+		found[album.ID] = album
+	}
+
+	return found, nil
+}
+
+// runBatch executes the prepared writes and responds with the per item results.
+// items maps every write back to the index of the batch item it came from.
+func runBatch(ctx context.Context, c *gin.Context, results []models.BatchItemResult, writes []mongo.WriteModel, items []int, atomic bool) {
+	if atomic && len(writes) != len(results) {
+		// all-or-nothing: a single bad item rejects the whole batch
+		for _, i := range items {
+			results[i].Status = models.BatchStatusSkipped
+		}
+
+		c.JSON(http.StatusUnprocessableEntity, models.BatchResponse{Results: results})
+		return
+	}
+
+	err := bulkWriteAlbums(ctx, writes, atomic)
+	if err == nil {
+		c.JSON(http.StatusOK, models.BatchResponse{Results: results})
+		return
+	}
+
+	var bulkErr mongo.BulkWriteException
+	isBulkErr := errors.As(err, &bulkErr)
+
+	if atomic || !isBulkErr {
+		// the transaction was rolled back, or we can't tell which writes went through
+		for _, i := range items {
+			results[i].Status = models.BatchStatusFailed
+			results[i].Error = err.Error()
+		}
+	}
+
+	if isBulkErr {
+		for _, writeErr := range bulkErr.WriteErrors {
+			i := items[writeErr.Index]
+			results[i].Status = models.BatchStatusFailed
+			results[i].Error = writeErr.Message
+		}
+	}
+
+	status := http.StatusOK
+	if atomic {
+		status = http.StatusInternalServerError
+	}
+
+	c.JSON(status, models.BatchResponse{Results: results})
+}
+
+// bulkWriteAlbums applies the writes unordered, or inside a transaction when atomic is set
+func bulkWriteAlbums(ctx context.Context, writes []mongo.WriteModel, atomic bool) error {
+	if len(writes) == 0 {
+		return nil
+	}
+
+	if !atomic {
+		_, err := albumsCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		return err
+	}
+
+	return inTransaction(ctx, func(sc mongo.SessionContext) error {
+		_, err := albumsCollection.BulkWrite(sc, writes)
+		return err
+	})
+}
+
+// inTransaction runs fn in a transaction, retrying it on transient errors
+func inTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+
+	return err
+}
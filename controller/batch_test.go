@@ -0,0 +1,111 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchAlbumsRoute(t *testing.T) {
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     []byte
+		token    string
+		statuses []string
+		status   int
+	}{
+		{
+			name:   "create albums in bulk",
+			method: "POST",
+			path:   "/albums:batchCreate",
+			body: []byte(`{"albums": [
+				{"title": "First album", "artist": "Me Owais", "price": 10},
+				{"title": "Second album", "price": 12}
+			]}`),
+			token:    "owais",
+			statuses: []string{models.BatchStatusCreated, models.BatchStatusInvalid},
+			status:   http.StatusOK,
+		},
+		{
+			name:   "reject the whole batch in atomic mode",
+			method: "POST",
+			path:   "/albums:batchCreate",
+			body: []byte(`{"atomic": true, "albums": [
+				{"title": "First album", "artist": "Me Owais", "price": 10},
+				{"title": "Second album", "price": 12}
+			]}`),
+			token:    "owais",
+			statuses: []string{models.BatchStatusSkipped, models.BatchStatusInvalid},
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "update albums with wrong ids",
+			method: "PATCH",
+			path:   "/albums:batchUpdate",
+			body: []byte(`{"albums": [
+				{"_id": "1", "price": 10},
+				{"_id": "000000000000000000000000", "price": 10}
+			]}`),
+			token:    "owais",
+			statuses: []string{models.BatchStatusInvalid, models.BatchStatusInvalid},
+			status:   http.StatusOK,
+		},
+		{
+			name:     "delete albums that do not exist",
+			method:   "POST",
+			path:     "/albums:batchDelete",
+			body:     []byte(`{"ids": ["62508b5c4f1f9b2d9c6f0000"]}`),
+			token:    "owais",
+			statuses: []string{models.BatchStatusNotFound},
+			status:   http.StatusOK,
+		},
+		{
+			name:   "try to delete albums with wrong token",
+			method: "POST",
+			path:   "/albums:batchDelete",
+			body:   []byte(`{"ids": ["62508b5c4f1f9b2d9c6f0000"]}`),
+			token:  "wrong_token",
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "try an unknown method",
+			method: "POST",
+			path:   "/albums:batchUpsert",
+			body:   []byte(`{}`),
+			token:  "owais",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, apiprefix+tc.path, bytes.NewBuffer(tc.body))
+			req.Header.Add("Authorization", tc.token)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.statuses != nil {
+				var res models.BatchResponse
+				json.Unmarshal(w.Body.Bytes(), &res)
+
+				statuses := make([]string, len(res.Results))
+				for i, r := range res.Results {
+					statuses[i] = r.Status
+				}
+
+				assert.Equal(t, tc.statuses, statuses)
+			}
+		})
+	}
+}
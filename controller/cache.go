@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"rest/models"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// clients may keep album responses but have to revalidate them on every use
+const albumCacheControl = "no-cache"
+
+// lastDeletion is the unix time of the latest album deletion. A deleted
+// album leaves no Updated_at behind, so list responses take it into account.
+// It starts at boot time since deletions before that are unknown.
+var lastDeletion = time.Now().Unix()
+
+func albumsDeleted() {
+	atomic.StoreInt64(&lastDeletion, time.Now().Unix())
+}
+
+// listModified returns when the catalog last changed. Lists look at every
+// album since any change can move an album in or out of a filtered page.
+func listModified(ctx context.Context) time.Time {
+	modified := time.Unix(atomic.LoadInt64(&lastDeletion), 0)
+
+	var latest models.Album
+
+	opts := options.FindOne().
+		SetSort(bson.M{"updated_at": -1}).
+		SetProjection(bson.M{"updated_at": 1})
+
+	err := albumsCollection.FindOne(ctx, bson.M{}, opts).Decode(&latest)
+
+	if err == nil && latest.Updated_at.After(modified) {
+		modified = latest.Updated_at
+	}
+
+	return modified
+}
+
+// notModified sets the caching headers for a response last changed at
+// modified and answers with 304 when the client copy is still current
+func notModified(c *gin.Context, modified time.Time) bool {
+	c.Header("Cache-Control", albumCacheControl)
+	c.Writer.Header().Add("Vary", "Accept")
+
+	if modified.IsZero() {
+		return false
+	}
+
+	// HTTP dates have a one second resolution
+	modified = modified.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", modified.Format(http.TimeFormat))
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || modified.After(since) {
+		return false
+	}
+
+	c.Status(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,680 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errCartChanged = errors.New("the cart changed meanwhile, try again")
+
+// how long an untouched cart is kept
+var cartTTL = 30 * 24 * time.Hour
+
+// the most of one album a cart holds
+const maxCartQuantity = 99
+
+func initCarts() {
+	if ttl := middlewares.DotEnvVariable("CART_TTL"); ttl != "" {
+		var err error
+		if cartTTL, err = time.ParseDuration(ttl); err != nil || cartTTL <= 0 {
+			log.Fatal("CART_TTL: invalid duration ", ttl)
+		}
+	}
+}
+
+// GetCart godoc
+// @Summary      Get the cart
+// @Description  get the cart of the user, or of the anonymous session, at the current prices of its albums
+// @Tags         cart
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  false  "ID of the signed in user"
+// @Param        X-User-Token  header    string  false  "Token signed for the user, see /users/{id}/token"
+// @Param        X-Session-ID  header    string  false  "Anonymous session ID, at least 16 characters"
+// @Param        currency      query     string  false  "ISO 4217 currency of the prices, the currency of the albums by default"
+// @Success      200  {object}  models.CartView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/cart [get]
+func GetCart(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	owner, ok := cartOwner(c)
+
+	if !ok {
+		return
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return
+	}
+
+	cart, _, _, err := loadCart(c, owner)
+
+	if err != nil {
+		log.Println("cart failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the cart"})
+		return
+	}
+
+	respondCart(c, cart, currency)
+}
+
+// PostCartItem godoc
+// @Summary      Add an album to the cart
+// @Description  put an album in the cart, the quantity adds up when it is there already
+// @Tags         cart
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string              false  "ID of the signed in user"
+// @Param        X-User-Token  header    string              false  "Token signed for the user, see /users/{id}/token"
+// @Param        X-Session-ID  header    string              false  "Anonymous session ID, at least 16 characters"
+// @Param        currency      query     string              false  "ISO 4217 currency of the prices, the currency of the albums by default"
+// @Param        item          body      models.AddCartItem  true   "Cart Item"
+// @Success      200  {object}  models.CartView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/cart/items [post]
+func PostCartItem(c *gin.Context) {
+	var add models.AddCartItem
+
+	owner, currency, ok := bindCartRequest(c, &add)
+
+	if !ok {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(add.AlbumID)
+
+	var album models.Album
+
+	if err != nil || albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album) != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	updateCart(c, owner, currency, func(cart *models.Cart) bool {
+		i := cartItemIndex(cart.Items, id)
+
+		if i < 0 {
+			cart.Items = append(cart.Items, models.CartItem{AlbumID: id})
+			i = len(cart.Items) - 1
+		}
+
+		if cart.Items[i].Quantity+add.Quantity > maxCartQuantity {
+			respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "a cart holds at most 99 of an album"})
+			return false
+		}
+
+		cart.Items[i].Quantity += add.Quantity
+		setCartItemPrice(&cart.Items[i], album)
+
+		return true
+	})
+}
+
+// UpdateCartItem godoc
+// @Summary      Change the quantity of an album in the cart
+// @Description  set the quantity of an album of the cart, 0 removes it. The album takes its current price.
+// @Tags         cart
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string                 false  "ID of the signed in user"
+// @Param        X-User-Token  header    string                 false  "Token signed for the user, see /users/{id}/token"
+// @Param        X-Session-ID  header    string                 false  "Anonymous session ID, at least 16 characters"
+// @Param        currency      query     string                 false  "ISO 4217 currency of the prices, the currency of the albums by default"
+// @Param        album         path      string                 true   "Album ID"
+// @Param        item          body      models.UpdateCartItem  true   "Quantity"
+// @Success      200  {object}  models.CartView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/cart/items/{album} [patch]
+func UpdateCartItem(c *gin.Context) {
+	var update models.UpdateCartItem
+
+	owner, currency, ok := bindCartRequest(c, &update)
+
+	if !ok {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("album"))
+
+	updateCart(c, owner, currency, func(cart *models.Cart) bool {
+		i := cartItemIndex(cart.Items, id)
+
+		if i < 0 {
+			respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not in the cart"})
+			return false
+		}
+
+		if *update.Quantity == 0 {
+			cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			return true
+		}
+
+		cart.Items[i].Quantity = *update.Quantity
+
+		var album models.Album
+
+		if albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album) == nil {
+			setCartItemPrice(&cart.Items[i], album)
+		}
+
+		return true
+	})
+}
+
+// DeleteCartItem godoc
+// @Summary      Remove an album from the cart
+// @Description  take an album out of the cart
+// @Tags         cart
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  false  "ID of the signed in user"
+// @Param        X-User-Token  header    string  false  "Token signed for the user, see /users/{id}/token"
+// @Param        X-Session-ID  header    string  false  "Anonymous session ID, at least 16 characters"
+// @Param        currency      query     string  false  "ISO 4217 currency of the prices, the currency of the albums by default"
+// @Param        album         path      string  true   "Album ID"
+// @Success      200  {object}  models.CartView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/cart/items/{album} [delete]
+func DeleteCartItem(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	owner, ok := cartOwner(c)
+
+	if !ok {
+		return
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("album"))
+
+	updateCart(c, owner, currency, func(cart *models.Cart) bool {
+		i := cartItemIndex(cart.Items, id)
+
+		if i < 0 {
+			respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not in the cart"})
+			return false
+		}
+
+		cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+
+		return true
+	})
+}
+
+// DeleteCart godoc
+// @Summary      Empty the cart
+// @Description  remove the cart of the user, or of the anonymous session
+// @Tags         cart
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  false  "ID of the signed in user"
+// @Param        X-User-Token  header    string  false  "Token signed for the user, see /users/{id}/token"
+// @Param        X-Session-ID  header    string  false  "Anonymous session ID, at least 16 characters"
+// @Success      200  {object}  models.SuccessMessage
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/cart [delete]
+func DeleteCart(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	owner, ok := cartOwner(c)
+
+	if !ok {
+		return
+	}
+
+	if _, err := cartsCollection.DeleteOne(c, owner); err != nil {
+		log.Println("cart failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not empty the cart"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully emptied the cart"})
+}
+
+// MergeCart godoc
+// @Summary      Merge the anonymous cart into the user's
+// @Description  at login, move the albums of the cart of the anonymous session to the cart of the user, quantities add up
+// @Tags         cart
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true   "ID of the signed in user"
+// @Param        X-User-Token  header    string  true   "Token signed for the user, see /users/{id}/token"
+// @Param        X-Session-ID  header    string  true   "Anonymous session ID, at least 16 characters"
+// @Param        currency      query     string  false  "ISO 4217 currency of the prices, the currency of the albums by default"
+// @Success      200  {object}  models.CartView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/cart/merge [post]
+func MergeCart(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	session, ok := currentSession(c)
+
+	if !ok {
+		return
+	}
+
+	if session == "" {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: sessionHeader + " header required"})
+		return
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return
+	}
+
+	var cart models.Cart
+
+	err := inTransaction(c, func(sc mongo.SessionContext) error {
+		anonymous, _, anonymousExists, err := loadCart(sc, bson.M{"session_id": session})
+
+		if err != nil {
+			return err
+		}
+
+		var previous []models.CartItem
+		var exists bool
+
+		if cart, previous, exists, err = loadCart(sc, bson.M{"user_id": user}); err != nil {
+			return err
+		}
+
+		if !anonymousExists {
+			return nil
+		}
+
+		for _, item := range anonymous.Items {
+			if i := cartItemIndex(cart.Items, item.AlbumID); i >= 0 {
+				cart.Items[i].Quantity += item.Quantity
+				if cart.Items[i].Quantity > maxCartQuantity {
+					cart.Items[i].Quantity = maxCartQuantity
+				}
+			} else {
+				cart.Items = append(cart.Items, item)
+			}
+		}
+
+		if err = storeCart(sc, &cart, previous, exists); err != nil {
+			return err
+		}
+
+		_, err = cartsCollection.DeleteOne(sc, bson.M{"_id": anonymous.ID})
+		return err
+	})
+
+	if err == errCartChanged {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("cart merge failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not merge the carts"})
+		return
+	}
+
+	respondCart(c, cart, currency)
+}
+
+// cartOwner is the filter of the cart of the request, the cart of the user
+// when there is one and of the anonymous session otherwise
+func cartOwner(c *gin.Context) (bson.M, bool) {
+	user, ok := currentUser(c)
+
+	if !ok {
+		return nil, false
+	}
+
+	if user != "" {
+		return bson.M{"user_id": user}, true
+	}
+
+	session, ok := currentSession(c)
+
+	if !ok {
+		return nil, false
+	}
+
+	if session == "" {
+		respond(c, http.StatusUnauthorized, models.ErrorMessage{Error: userHeader + " or " + sessionHeader + " header required"})
+		return nil, false
+	}
+
+	return bson.M{"session_id": session}, true
+}
+
+func bindCartRequest(c *gin.Context, req interface{}) (bson.M, string, bool) {
+	if !negotiate(c) {
+		return nil, "", false
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return nil, "", false
+	}
+
+	owner, ok := cartOwner(c)
+
+	if !ok {
+		return nil, "", false
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return nil, "", false
+	}
+
+	if err := c.ShouldBindWith(req, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return nil, "", false
+	}
+
+	if validationErr := validate.Struct(req); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return nil, "", false
+	}
+
+	return owner, currency, true
+}
+
+// loadCart finds the cart of owner, or starts a new one when there is none.
+// A cart that expired but was not removed yet starts over empty. previous
+// are the items as stored, for storeCart.
+func loadCart(ctx context.Context, owner bson.M) (cart models.Cart, previous []models.CartItem, exists bool, err error) {
+	err = cartsCollection.FindOne(ctx, owner).Decode(&cart)
+
+	if err == mongo.ErrNoDocuments {
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+		cart = models.Cart{ID: primitive.NewObjectID(), Items: []models.CartItem{}, Created_at: now, Updated_at: now}
+		cart.UserID, _ = owner["user_id"].(string)
+		cart.SessionID, _ = owner["session_id"].(string)
+
+		return cart, nil, false, nil
+	}
+
+	if err != nil {
+		return cart, nil, false, err
+	}
+
+	previous = cart.Items
+	cart.Items = append([]models.CartItem{}, previous...)
+
+	if !time.Now().Before(cart.Expires_at) {
+		cart.Items = []models.CartItem{}
+	}
+
+	return cart, previous, true, nil
+}
+
+// storeCart writes the cart when it still has the previous items, so
+// concurrent changes don't overwrite each other, and pushes its expiry back
+func storeCart(ctx context.Context, cart *models.Cart, previous []models.CartItem, exists bool) error {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	cart.Updated_at = now
+	cart.Expires_at = now.Add(cartTTL)
+
+	if !exists {
+		_, err := cartsCollection.InsertOne(ctx, cart)
+
+		if mongo.IsDuplicateKeyError(err) {
+			return errCartChanged
+		}
+
+		return err
+	}
+
+	res, err := cartsCollection.ReplaceOne(ctx, bson.M{"_id": cart.ID, "items": previous}, cart)
+
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return errCartChanged
+	}
+
+	return nil
+}
+
+// updateCart applies change to the cart of owner and answers with the cart.
+// change responds itself when it refuses.
+func updateCart(c *gin.Context, owner bson.M, currency string, change func(cart *models.Cart) bool) {
+	cart, previous, exists, err := loadCart(c, owner)
+
+	if err != nil {
+		log.Println("cart failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the cart"})
+		return
+	}
+
+	if !change(&cart) {
+		return
+	}
+
+	err = storeCart(c, &cart, previous, exists)
+
+	if err == errCartChanged {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("cart failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not update the cart"})
+		return
+	}
+
+	respondCart(c, cart, currency)
+}
+
+func respondCart(c *gin.Context, cart models.Cart, currency string) {
+	ids := make([]primitive.ObjectID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		ids = append(ids, item.AlbumID)
+	}
+
+	albums, err := findAlbums(c, ids)
+
+	if err != nil {
+		log.Println("cart failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the cart"})
+		return
+	}
+
+	view, err := cartView(cart, albums, currency)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	respond(c, http.StatusOK, view)
+}
+
+// cartView checks the cart against the albums as they are now, with the
+// prices in currency. Without a currency the cart takes the one all its
+// albums share, or the default currency.
+func cartView(cart models.Cart, albums map[primitive.ObjectID]models.Album, currency string) (models.CartView, error) {
+	if currency == "" {
+		currency = cartCurrency(albums)
+	}
+
+	view := models.CartView{
+		ID:         cart.ID,
+		UserID:     cart.UserID,
+		SessionID:  cart.SessionID,
+		Items:      []models.CartLine{},
+		Expires_at: cart.Expires_at,
+		Updated_at: cart.Updated_at,
+	}
+
+	places := currencyPlaces(currency)
+	zero, _ := models.DecimalFromRat(new(big.Rat), places)
+	subtotal := new(big.Rat)
+
+	for _, item := range cart.Items {
+		added, _ := convertPrice(item.Price, item.Currency, item.Currency)
+
+		line := models.CartLine{
+			AlbumID:    item.AlbumID,
+			Quantity:   item.Quantity,
+			AddedPrice: added,
+			Total:      models.Money{Amount: zero, Currency: currency},
+		}
+
+		album, found := albums[item.AlbumID]
+
+		if !found {
+			line.Price = line.AddedPrice
+			line.Problem = "album not found"
+			view.Items = append(view.Items, line)
+			continue
+		}
+
+		albumCurrency := album.Currency
+		if albumCurrency == "" {
+			albumCurrency = defaultCurrency
+		}
+
+		line.Title = album.Title
+		line.Artist = album.Artist
+		line.PriceChanged = album.Price.Cmp(item.Price) != 0 || albumCurrency != item.Currency
+
+		var err error
+
+		if line.Price, err = convertPrice(album.Price, albumCurrency, currency); err != nil {
+			return view, noExchangeRate(albumCurrency, currency)
+		}
+
+		// albums without inventory don't track their stock
+		line.Available = album.Inventory == nil || album.Inventory.Available >= item.Quantity
+
+		if !line.Available {
+			line.Problem = "not enough stock"
+			view.Items = append(view.Items, line)
+			continue
+		}
+
+		total := line.Price.Amount.Rat()
+		total.Mul(total, big.NewRat(int64(item.Quantity), 1))
+		subtotal.Add(subtotal, total)
+
+		line.Total.Amount, _ = models.DecimalFromRat(total, places)
+		view.Items = append(view.Items, line)
+	}
+
+	view.Subtotal.Amount, _ = models.DecimalFromRat(subtotal, places)
+	view.Subtotal.Currency = currency
+
+	return view, nil
+}
+
+// cartCurrency is the currency all the albums share, or the default currency
+func cartCurrency(albums map[primitive.ObjectID]models.Album) string {
+	currency := ""
+
+	for _, album := range albums {
+		albumCurrency := album.Currency
+		if albumCurrency == "" {
+			albumCurrency = defaultCurrency
+		}
+
+		if currency != "" && currency != albumCurrency {
+			return defaultCurrency
+		}
+
+		currency = albumCurrency
+	}
+
+	if currency == "" {
+		return defaultCurrency
+	}
+
+	return currency
+}
+
+func cartItemIndex(items []models.CartItem, albumID primitive.ObjectID) int {
+	for i, item := range items {
+		if item.AlbumID == albumID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// setCartItemPrice takes the current price of the album
+func setCartItemPrice(item *models.CartItem, album models.Album) {
+	item.Price = album.Price
+	item.Currency = album.Currency
+
+	if item.Currency == "" {
+		item.Currency = defaultCurrency
+	}
+
+	if item.Added_at.IsZero() {
+		item.Added_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,141 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCartRoutes(t *testing.T) {
+
+	const user = "cart-test-user"
+	const session = "cart-test-session-0001"
+
+	anonymous := map[string]string{"X-Session-ID": session}
+	signedIn := asUser(t, user)
+	login := withHeaders(signedIn, anonymous)
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Cart album", "artist": "Me Owais", "price": 20, "currency": "USD"}`, admin).Body.Bytes(), &postRes)
+	albumID := postRes.InsertedID
+
+	request("DELETE", "/cart", "", withHeaders(admin, anonymous))
+	request("DELETE", "/cart", "", withHeaders(admin, signedIn))
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:     "try to read a cart without saying whose",
+			method:   "GET",
+			path:     "/cart",
+			response: `{"error":"X-User-ID or X-Session-ID header required"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to use a short session ID",
+			method:   "GET",
+			path:     "/cart",
+			headers:  map[string]string{"X-Session-ID": "short"},
+			response: `{"error":"invalid X-Session-ID"}`,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:    "add an album to the anonymous cart",
+			method:  "POST",
+			path:    "/cart/items",
+			body:    `{"album_id": "` + albumID + `", "quantity": 2}`,
+			headers: anonymous,
+			status:  http.StatusOK,
+		},
+		{
+			name:     "try to add a missing album",
+			method:   "POST",
+			path:     "/cart/items",
+			body:     `{"album_id": "000000000000000000000000", "quantity": 1}`,
+			headers:  anonymous,
+			response: `{"error":"album not found"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:    "try to add too many",
+			method:  "POST",
+			path:    "/cart/items",
+			body:    `{"album_id": "` + albumID + `", "quantity": 98}`,
+			headers: anonymous,
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "add the album to the user cart",
+			method:  "POST",
+			path:    "/cart/items",
+			body:    `{"album_id": "` + albumID + `", "quantity": 1}`,
+			headers: signedIn,
+			status:  http.StatusOK,
+		},
+		{
+			name:     "change the price",
+			method:   "PATCH",
+			path:     "/albums/" + albumID,
+			body:     `{"price": 25}`,
+			response: `{"message":"successfully updated the album"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:    "merge the anonymous cart at login",
+			method:  "POST",
+			path:    "/cart/merge",
+			headers: login,
+			status:  http.StatusOK,
+		},
+		{
+			name:     "try to change an album that is not in the cart",
+			method:   "PATCH",
+			path:     "/cart/items/000000000000000000000000",
+			body:     `{"quantity": 1}`,
+			headers:  signedIn,
+			response: `{"error":"album not in the cart"}`,
+			status:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, withHeaders(admin, tc.headers))
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var cart models.CartView
+	json.Unmarshal(request("GET", "/cart", "", withHeaders(admin, signedIn)).Body.Bytes(), &cart)
+
+	if assert.Len(t, cart.Items, 1) {
+		assert.Equal(t, 3, cart.Items[0].Quantity)
+		assert.True(t, cart.Items[0].PriceChanged)
+		assert.Equal(t, "20.00", cart.Items[0].AddedPrice.Amount.String())
+		assert.Equal(t, "25.00", cart.Items[0].Price.Amount.String())
+	}
+
+	assert.Equal(t, "75.00", cart.Subtotal.Amount.String())
+
+	var emptied models.CartView
+	json.Unmarshal(request("GET", "/cart", "", withHeaders(admin, anonymous)).Body.Bytes(), &emptied)
+	assert.Empty(t, emptied.Items)
+
+	assert.Equal(t, http.StatusOK, request("DELETE", "/cart", "", withHeaders(admin, signedIn)).Code)
+	assert.Equal(t, http.StatusOK, request("DELETE", "/albums/"+albumID, "", admin).Code)
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"math/big"
+	"os"
+	"rest/middlewares"
+	"rest/models"
+	"rest/shipping"
+	"rest/tax"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	taxCalculator      tax.Calculator      = &tax.Table{}
+	shippingCalculator shipping.Calculator = shipping.Free{}
+)
+
+var errAddressRequired = errors.New("a shipping address is required")
+
+func initTaxAndShipping() {
+	if path := middlewares.DotEnvVariable("TAX_RATES_FILE"); path != "" {
+		file, err := os.Open(path)
+
+		if err != nil {
+			log.Fatal("TAX_RATES_FILE: ", err)
+		}
+		defer file.Close()
+
+		table, err := tax.ReadTable(file)
+
+		if err != nil {
+			log.Fatal("TAX_RATES_FILE: ", err)
+		}
+
+		taxCalculator = table
+	}
+
+	if path := middlewares.DotEnvVariable("SHIPPING_RATES_FILE"); path != "" {
+		file, err := os.Open(path)
+
+		if err != nil {
+			log.Fatal("SHIPPING_RATES_FILE: ", err)
+		}
+		defer file.Close()
+
+		table, err := shipping.ReadTable(file)
+
+		if err != nil {
+			log.Fatal("SHIPPING_RATES_FILE: ", err)
+		}
+
+		shippingCalculator = table
+	}
+}
+
+// albumWeight is the shipping weight of an album in grams, the weight of its
+// format when it has none of its own
+func albumWeight(album models.Album) int {
+	if album.Weight > 0 {
+		return album.Weight
+	}
+
+	return shippingCalculator.Weight(album.Format)
+}
+
+// parcelWeight is the shipping weight of the quoted albums in grams, 0 when
+// they are all digital
+func parcelWeight(quote models.Quote, albums map[primitive.ObjectID]models.Album) int {
+	weight := 0
+
+	for _, line := range quote.Items {
+		weight += line.Quantity * albumWeight(albums[line.AlbumID])
+	}
+
+	return weight
+}
+
+// addTaxAndShipping adds what shipping the quoted albums to destination costs
+// and the tax of destination to the quote. Without a destination the quote
+// has neither.
+func addTaxAndShipping(quote *models.Quote, albums map[primitive.ObjectID]models.Album, destination *models.Destination) error {
+	currency := quote.Total.Currency
+	goods := quote.Total.Amount.Rat()
+
+	quote.Shipping = money(new(big.Rat), currency)
+	quote.Tax = money(new(big.Rat), currency)
+
+	if destination == nil {
+		return nil
+	}
+
+	// the shipping table has prices of its own currency, so the free shipping
+	// thresholds are compared in it too
+	tableCurrency := shippingCalculator.Currency()
+
+	if tableCurrency == "" {
+		tableCurrency = currency
+	}
+
+	value, err := convertRat(goods, currency, tableCurrency)
+
+	if err != nil {
+		return noExchangeRate(currency, tableCurrency)
+	}
+
+	cost, err := shippingCalculator.Cost(destination.Country, shipping.Parcel{Weight: parcelWeight(*quote, albums), Value: value})
+
+	if err != nil {
+		return err
+	}
+
+	if cost, err = convertRat(cost, tableCurrency, currency); err != nil {
+		return noExchangeRate(tableCurrency, currency)
+	}
+
+	quote.Shipping = money(cost, currency)
+
+	rate := taxCalculator.Rate(destination.Country, destination.Region)
+	base := new(big.Rat).Set(goods)
+
+	if rate.Shipping {
+		base.Add(base, quote.Shipping.Amount.Rat())
+	}
+
+	taxed := new(big.Rat).Mul(base, rate.Percent)
+	quote.Tax = money(taxed.Quo(taxed, big.NewRat(100, 1)), currency)
+	quote.TaxName = rate.Name
+
+	total := new(big.Rat).Add(goods, quote.Shipping.Amount.Rat())
+	quote.Total = money(total.Add(total, quote.Tax.Amount.Rat()), currency)
+
+	return nil
+}
+
+func prepareDestination(destination *models.Destination) {
+	destination.Country = strings.ToUpper(strings.TrimSpace(destination.Country))
+	destination.Region = strings.ToUpper(strings.TrimSpace(destination.Region))
+}
+
+func prepareAddress(address *models.Address) {
+	address.Name = strings.TrimSpace(address.Name)
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	address.Region = strings.ToUpper(strings.TrimSpace(address.Region))
+}
@@ -0,0 +1,91 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckoutRoutes(t *testing.T) {
+
+	const user = "checkout-test-user"
+
+	customer := asUser(t, user)
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Heavy album", "artist": "Me Owais", "price": 10, "currency": "USD", "format": "vinyl"}`, admin).Body.Bytes(), &postRes)
+	albumID := postRes.InsertedID
+
+	request("DELETE", "/cart", "", withHeaders(admin, customer))
+	request("POST", "/cart/items", `{"album_id": "`+albumID+`", "quantity": 1}`, withHeaders(admin, customer))
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:   "try to quote for an unknown country",
+			method: "POST",
+			path:   "/pricing/quote",
+			body:   `{"items": [{"album_id": "` + albumID + `", "quantity": 1}], "destination": {"country": "XX"}}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to order a vinyl without an address",
+			method:   "POST",
+			path:     "/orders",
+			headers:  customer,
+			response: `{"error":"a shipping address is required"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "try to order with half an address",
+			method:  "POST",
+			path:    "/orders",
+			body:    `{"address": {"name": "Me Owais", "country": "US"}}`,
+			headers: customer,
+			status:  http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, withHeaders(admin, tc.headers))
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var quote models.Quote
+	json.Unmarshal(request("POST", "/pricing/quote", `{"items": [{"album_id": "`+albumID+`", "quantity": 2}], "destination": {"country": "us", "region": "ca"}}`, admin).Body.Bytes(), &quote)
+
+	// shipping is free and nothing is taxed without rate files
+	assert.Equal(t, "0.00", quote.Shipping.Amount.String())
+	assert.Equal(t, "0.00", quote.Tax.Amount.String())
+	assert.Equal(t, "20.00", quote.Total.Amount.String())
+
+	var order models.Order
+	w := request("POST", "/orders", `{"address": {"name": "Me Owais", "line1": "1 Main St", "city": "Springfield", "postal_code": "12345", "region": "il", "country": "us"}}`, withHeaders(admin, customer))
+	json.Unmarshal(w.Body.Bytes(), &order)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, order.ShippingAddress) {
+		assert.Equal(t, "US", order.ShippingAddress.Country)
+		assert.Equal(t, "IL", order.ShippingAddress.Region)
+	}
+
+	assert.Equal(t, http.StatusOK, request("POST", "/orders/"+order.ID.Hex()+"/cancel", "", withHeaders(admin, customer)).Code)
+	assert.Equal(t, http.StatusOK, request("DELETE", "/albums/"+albumID, "", admin).Code)
+}
@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"rest/imaging"
+	"rest/middlewares"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// largest cover image accepted
+const maxCoverSize = 10 << 20
+
+// PutAlbumCover godoc
+// @Summary      Upload an album cover
+// @Description  upload a JPEG, PNG or WebP cover of at most 10 MB as the "cover" form field, replacing the previous one. Thumbnails are made in the small, medium and large sizes.
+// @Tags         albums
+// @Accept       mpfd
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string  true  "Album ID"
+// @Param        cover  formData  file    true  "Cover image"
+// @Success      200	{object}  models.Cover
+// @Failure      404	{object}  models.ErrorMessage
+// @Failure      413	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/cover [put]
+func PutAlbumCover(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var album models.Album
+
+	if err := albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	// leave room for the rest of the multipart body
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCoverSize+1<<20)
+
+	header, err := c.FormFile("cover")
+
+	if err != nil {
+		if err.Error() == "http: request body too large" {
+			respond(c, http.StatusRequestEntityTooLarge, models.ErrorMessage{Error: "cover is larger than 10 MB"})
+			return
+		}
+
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "missing cover file"})
+		return
+	}
+
+	if header.Size > maxCoverSize {
+		respond(c, http.StatusRequestEntityTooLarge, models.ErrorMessage{Error: "cover is larger than 10 MB"})
+		return
+	}
+
+	file, err := header.Open()
+
+	if err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "missing cover file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+
+	if err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "could not read the cover"})
+		return
+	}
+
+	// trust the bytes, not the file name or the part's Content-Type
+	contentType := http.DetectContentType(data)
+
+	if _, ok := imaging.ContentTypes[contentType]; !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "cover must be a JPEG, PNG or WebP image"})
+		return
+	}
+
+	img, format, err := imaging.Decode(data)
+
+	if err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "invalid image: " + err.Error()})
+		return
+	}
+
+	cover, err := storeCover(album.ID, data, contentType, img, format)
+
+	if err != nil {
+		deleteCoverImages(cover)
+		log.Println("cover upload failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not store the cover"})
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	cover.Updated_at = now
+
+	res, err := albumsCollection.UpdateByID(c, album.ID, bson.M{"$set": bson.M{"cover": cover, "updated_at": now}})
+
+	if err != nil || res.MatchedCount == 0 {
+		// the album is gone, the new images belong to nobody
+		deleteCoverImages(cover)
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	deleteCoverImages(album.Cover)
+
+	respond(c, http.StatusOK, cover)
+}
+
+// GetAlbumCover godoc
+// @Summary      Get an album cover
+// @Description  get the cover image of an album, supports Range and conditional requests
+// @Tags         albums
+// @Produce      image/jpeg,image/png,image/webp
+// @Param        id    path      string  true   "Album ID"
+// @Param        size  query     string  false  "original (default), small, medium or large"
+// @Param        If-None-Match  header  string  false  "Answer with 304 when the ETag still matches"
+// @Param        Range          header  string  false  "Byte range, answered with 206"
+// @Success      200  {file}    file
+// @Success      206  {file}    file
+// @Success      304  "Not Modified"
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/cover [get]
+func GetAlbumCover(c *gin.Context) {
+	size := c.DefaultQuery("size", imaging.Original)
+
+	if !coverSize(size) {
+		c.JSON(http.StatusBadRequest, models.ErrorMessage{Error: "size must be original, small, medium or large"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var album models.Album
+
+	opts := options.FindOne().SetProjection(bson.M{"cover": 1})
+
+	if err := albumsCollection.FindOne(c, bson.M{"_id": id}, opts).Decode(&album); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	if album.Cover == nil {
+		c.JSON(http.StatusNotFound, models.ErrorMessage{Error: "album has no cover"})
+		return
+	}
+
+	for _, file := range album.Cover.Images {
+		if file.Size == size {
+			c.Header("Cache-Control", albumCacheControl)
+			serveFile(c, coversBucket, file.FileID, file.Length, file.ContentType, file.ETag, album.Cover.Updated_at)
+			return
+		}
+	}
+
+	c.JSON(http.StatusNotFound, models.ErrorMessage{Error: "album has no cover"})
+}
+
+func coverSize(size string) bool {
+	if size == imaging.Original {
+		return true
+	}
+
+	for _, s := range imaging.Sizes {
+		if s.Name == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+// storeCover uploads the original image and its thumbnails. On error the
+// returned cover lists the images stored so far, for cleaning up.
+func storeCover(albumID primitive.ObjectID, data []byte, contentType string, img image.Image, format string) (*models.Cover, error) {
+	cover := &models.Cover{}
+	bounds := img.Bounds()
+
+	original, err := uploadCoverImage(albumID, imaging.Original, data, contentType, bounds.Dx(), bounds.Dy())
+
+	if err != nil {
+		return cover, err
+	}
+
+	cover.Images = append(cover.Images, original)
+
+	for _, size := range imaging.Sizes {
+		thumb := imaging.Thumbnail(img, size.Max)
+
+		var buf bytes.Buffer
+
+		thumbType, err := imaging.Encode(&buf, thumb, format)
+
+		if err != nil {
+			return cover, err
+		}
+
+		stored, err := uploadCoverImage(albumID, size.Name, buf.Bytes(), thumbType, thumb.Bounds().Dx(), thumb.Bounds().Dy())
+
+		if err != nil {
+			return cover, err
+		}
+
+		cover.Images = append(cover.Images, stored)
+	}
+
+	return cover, nil
+}
+
+func uploadCoverImage(albumID primitive.ObjectID, size string, data []byte, contentType string, width, height int) (models.CoverImage, error) {
+	sum := sha256.Sum256(data)
+
+	stored := models.CoverImage{
+		Size:        size,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+		Length:      int64(len(data)),
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+
+	metadata := bson.M{"album_id": albumID, "size": size, "content_type": contentType}
+
+	fileID, err := coversBucket.UploadFromStream(albumID.Hex()+"/"+size, bytes.NewReader(data),
+		options.GridFSUpload().SetMetadata(metadata))
+
+	stored.FileID = fileID
+
+	return stored, err
+}
+
+// deleteCoverImages removes the files of a cover. Leftover files only waste
+// space, so failures are logged and otherwise ignored.
+func deleteCoverImages(cover *models.Cover) {
+	if cover == nil {
+		return
+	}
+
+	for _, file := range cover.Images {
+		if file.FileID.IsZero() {
+			continue
+		}
+
+		if err := coversBucket.Delete(file.FileID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			log.Println("could not delete cover image", file.FileID.Hex()+":", err)
+		}
+	}
+}
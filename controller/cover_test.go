@@ -0,0 +1,135 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumCoverRoutes(t *testing.T) {
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", apiprefix+"/albums", bytes.NewBufferString(`{
+		"title": "Cover album",
+		"artist": "Me Owais",
+		"price": 10
+	}`))
+	req.Header.Add("Authorization", "owais")
+	router.ServeHTTP(w, req)
+
+	var postRes PostResponse
+	json.Unmarshal(w.Body.Bytes(), &postRes)
+	coverPath := apiprefix + "/albums/" + postRes.InsertedID + "/cover"
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 800, 400)))
+
+	upload := func(data []byte) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("cover", "cover.png")
+		part.Write(data)
+		form.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", coverPath, &body)
+		req.Header.Add("Authorization", "owais")
+		req.Header.Add("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	t.Run("try to upload a cover that is not an image", func(t *testing.T) {
+		w := upload([]byte("just some text"))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("upload a cover", func(t *testing.T) {
+		w := upload(img.Bytes())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	var etag string
+
+	test_cases := []struct {
+		name        string
+		query       string
+		header      map[string]string
+		contentType string
+		status      int
+	}{
+		{
+			name:        "get the original cover",
+			contentType: "image/png",
+			status:      http.StatusOK,
+		},
+		{
+			name:        "get a thumbnail",
+			query:       "?size=small",
+			contentType: "image/png",
+			status:      http.StatusOK,
+		},
+		{
+			name:   "try to get a size that does not exist",
+			query:  "?size=huge",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:        "get part of the cover",
+			header:      map[string]string{"Range": "bytes=0-9"},
+			contentType: "image/png",
+			status:      http.StatusPartialContent,
+		},
+		{
+			name:   "get an unchanged cover",
+			header: map[string]string{"If-None-Match": ""},
+			status: http.StatusNotModified,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", coverPath+tc.query, nil)
+
+			for key, value := range tc.header {
+				if key == "If-None-Match" {
+					value = etag
+				}
+				req.Header.Add(key, value)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.contentType != "" {
+				assert.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+			}
+
+			if tc.status == http.StatusPartialContent {
+				assert.Equal(t, 10, w.Body.Len())
+			}
+
+			if etag == "" {
+				etag = w.Header().Get("ETag")
+			}
+		})
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", apiprefix+"/albums/"+postRes.InsertedID, nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
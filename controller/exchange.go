@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"rest/middlewares"
+	"rest/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the currency of albums created without one
+var defaultCurrency = "USD"
+
+var (
+	ratesMu       sync.RWMutex
+	exchangeRates *models.ExchangeRates
+)
+
+var errNoExchangeRate = errors.New("no exchange rate")
+
+// currencies whose minor unit isn't the cent, by ISO 4217
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+func initExchangeRates() {
+	if currency := middlewares.DotEnvVariable("DEFAULT_CURRENCY"); currency != "" {
+		defaultCurrency = strings.ToUpper(currency)
+	}
+
+	if err := validate.Var(defaultCurrency, "iso4217"); err != nil {
+		log.Fatal("DEFAULT_CURRENCY: ", err)
+	}
+
+	path := middlewares.DotEnvVariable("EXCHANGE_RATES_FILE")
+
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		log.Fatal("EXCHANGE_RATES_FILE: ", err)
+	}
+
+	var rates models.ExchangeRates
+
+	if err = json.Unmarshal(data, &rates); err != nil {
+		log.Fatal("EXCHANGE_RATES_FILE: ", err)
+	}
+
+	if err = setExchangeRates(&rates); err != nil {
+		log.Fatal("EXCHANGE_RATES_FILE: ", err)
+	}
+}
+
+// GetExchangeRates godoc
+// @Summary      Get the exchange rates
+// @Description  get the exchange rates prices are converted with
+// @Tags         currencies
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Success      200  {object}  models.ExchangeRates
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v2/exchange-rates [get]
+func GetExchangeRates(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	ratesMu.RLock()
+	rates := exchangeRates
+	ratesMu.RUnlock()
+
+	if rates == nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "no exchange rates loaded"})
+		return
+	}
+
+	respond(c, http.StatusOK, rates)
+}
+
+// PutExchangeRates godoc
+// @Summary      Replace the exchange rates
+// @Description  replace the exchange rates of the running server, the rates are how much of each currency one unit of the base buys
+// @Tags         currencies
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        rates  body      models.ExchangeRates  true  "Exchange Rates"
+// @Success      200	{object}  models.ExchangeRates
+// @Failure      406	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v2/exchange-rates [put]
+func PutExchangeRates(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var rates models.ExchangeRates
+
+	if err := c.ShouldBindWith(&rates, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if err := setExchangeRates(&rates); err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	respond(c, http.StatusOK, rates)
+}
+
+// setExchangeRates checks the rates and makes them the ones in use
+func setExchangeRates(rates *models.ExchangeRates) error {
+	rates.Base = strings.ToUpper(rates.Base)
+
+	normalized := make(map[string]models.Decimal, len(rates.Rates)+1)
+
+	for currency, rate := range rates.Rates {
+		if r := rate.Rat(); r == nil || r.Sign() <= 0 {
+			return errors.New("the rate of " + currency + " is not a positive number")
+		}
+
+		normalized[strings.ToUpper(currency)] = rate
+	}
+
+	normalized[rates.Base], _ = models.ParseDecimal("1")
+	rates.Rates = normalized
+
+	if err := validate.Struct(rates); err != nil {
+		return err
+	}
+
+	rates.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	ratesMu.Lock()
+	exchangeRates = rates
+	ratesMu.Unlock()
+
+	return nil
+}
+
+// ratesModified is when the exchange rates were last replaced
+func ratesModified() time.Time {
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+
+	if exchangeRates == nil {
+		return time.Time{}
+	}
+
+	return exchangeRates.Updated_at
+}
+
+// convertPrice turns an amount of the from currency into money of the to
+// currency, rounded to the minor unit of the to currency
+func convertPrice(amount models.Amount, from, to string) (models.Money, error) {
+	if from == "" {
+		from = defaultCurrency
+	}
+
+	value, err := convertRat(amount.Decimal().Rat(), from, to)
+
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	converted, err := models.DecimalFromRat(value, currencyPlaces(to))
+
+	return models.Money{Amount: converted, Currency: to}, err
+}
+
+// convertRat turns an exact value of the from currency into the to
+// currency, without rounding
+func convertRat(value *big.Rat, from, to string) (*big.Rat, error) {
+	if from == to {
+		return new(big.Rat).Set(value), nil
+	}
+
+	ratesMu.RLock()
+	rates := exchangeRates
+	ratesMu.RUnlock()
+
+	if rates == nil {
+		return nil, errNoExchangeRate
+	}
+
+	fromRate, ok := rates.Rates[from]
+	toRate, ok2 := rates.Rates[to]
+
+	if !ok || !ok2 {
+		return nil, errNoExchangeRate
+	}
+
+	converted := new(big.Rat).Mul(value, toRate.Rat())
+
+	return converted.Quo(converted, fromRate.Rat()), nil
+}
+
+// currencyPlaces is the number of decimals of the minor unit of currency
+func currencyPlaces(currency string) int {
+	if places, ok := minorUnits[currency]; ok {
+		return places
+	}
+
+	return 2
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"rest/export"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportAlbums godoc
+// @Summary      Export albums
+// @Description  stream the albums matching the list filters as a file download
+// @Tags         albums
+// @Produce      text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format     query     string  false  "csv (default), ndjson or xlsx"
+// @Param        title      query     string  false  "Title contains"
+// @Param        artist     query     string  false  "Artist name"
+// @Param        min_price  query     number  false  "Minimum price"
+// @Param        max_price  query     number  false  "Maximum price"
+// @Param        genre      query     string  false  "Genre ID, albums of its sub-genres match too"
+// @Param        tag        query     string  false  "Comma separated tags the albums all carry"
+// @Param        label      query     string  false  "Label ID"
+// @Param        format     query     string  false  "Release format, one of cd, vinyl or digital"
+// @Param        country    query     string  false  "ISO 3166-1 alpha-2 country of release"
+// @Param        catalog_number  query  string  false  "Catalog number"
+// @Param        barcode    query     string  false  "UPC or EAN barcode"
+// @Param        released_from  query  string  false  "Released on or after, 2006-01-02"
+// @Param        released_to    query  string  false  "Released on or before, 2006-01-02"
+// @Param        low_stock      query  bool    false  "Only the albums whose available stock dropped to their low stock threshold"
+// @Success      200  {file}    file
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/export [get]
+func ExportAlbums(c *gin.Context) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.CSV)))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := albumFilter(c)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the request context stops the export when the client goes away
+	ctx := c.Request.Context()
+
+	cursor, err := albumsCollection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}).SetBatchSize(500))
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not export albums"})
+		return
+	}
+	defer cursor.Close(ctx)
+
+	filename := "albums-" + time.Now().Format("20060102") + "." + string(format)
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer)
+
+	// headers are already sent, so failures past this point can only cut the download short
+	for err == nil && cursor.Next(ctx) {
+		var album models.Album
+
+		if err = cursor.Decode(&album); err == nil {
+			if album.Currency == "" {
+				album.Currency = defaultCurrency
+			}
+
+			err = w.Write(album)
+		}
+	}
+
+	if err == nil {
+		err = cursor.Err()
+	}
+
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err != nil {
+		log.Println("album export failed:", err)
+		c.Abort()
+	}
+}
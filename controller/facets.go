@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"rest/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// artists listed in the artist facet, the most frequent first
+const maxFacetValues = 20
+
+// price bucket boundaries used when neither PRICE_BUCKETS nor the
+// price_buckets query parameter set them
+var defaultPriceBuckets = []float64{0, 10, 20, 50, 100}
+
+// requestedFacets parses the comma separated facets query parameter
+func requestedFacets(c *gin.Context) ([]string, error) {
+	value := c.Query("facets")
+	if value == "" {
+		return nil, nil
+	}
+
+	var names []string
+
+	for _, name := range strings.Split(value, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "artist", "price_bucket", "year":
+			names = append(names, name)
+		default:
+			return nil, errors.New("unknown facet " + name)
+		}
+	}
+
+	return names, nil
+}
+
+// parsePriceBuckets parses ascending bucket boundaries like "0,10,20,50"
+func parsePriceBuckets(value string) ([]float64, error) {
+	var boundaries []float64
+
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, errors.New("invalid price_buckets")
+		}
+
+		boundaries = append(boundaries, n)
+	}
+
+	for i := 1; i < len(boundaries); i++ {
+		if boundaries[i] <= boundaries[i-1] {
+			return nil, errors.New("price_buckets must be in ascending order")
+		}
+	}
+
+	return boundaries, nil
+}
+
+func priceBuckets(c *gin.Context) ([]float64, error) {
+	if value := c.Query("price_buckets"); value != "" {
+		return parsePriceBuckets(value)
+	}
+
+	return defaultPriceBuckets, nil
+}
+
+// albumFacets counts the albums matching filter per value of every named facet
+// in a single $facet aggregation
+func albumFacets(ctx context.Context, filter bson.M, names []string, boundaries []float64) (models.Facets, error) {
+	var facets models.Facets
+
+	stages := bson.M{}
+
+	for _, name := range names {
+		switch name {
+		case "artist":
+			stages[name] = bson.A{
+				bson.M{"$group": bson.M{"_id": "$artist", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": maxFacetValues},
+			}
+		case "price_bucket":
+			stages[name] = bson.A{
+				bson.M{"$bucket": bson.M{
+					"groupBy":    "$price",
+					"boundaries": append(append([]float64{}, boundaries...), math.Inf(1)),
+					// prices below the first boundary
+					"default": "other",
+					"output":  bson.M{"count": bson.M{"$sum": 1}},
+				}},
+			}
+		case "year":
+			// the year the album was added to the catalog
+			stages[name] = bson.A{
+				bson.M{"$group": bson.M{"_id": bson.M{"$year": "$created_at"}, "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			}
+		}
+	}
+
+	if len(stages) == 0 {
+		return facets, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: stages}},
+	}
+
+	cursor, err := albumsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return facets, err
+	}
+
+	var results []struct {
+		Artist []struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		} `bson:"artist"`
+		PriceBucket []struct {
+			ID    interface{} `bson:"_id"`
+			Count int         `bson:"count"`
+		} `bson:"price_bucket"`
+		Year []struct {
+			ID    int `bson:"_id"`
+			Count int `bson:"count"`
+		} `bson:"year"`
+	}
+
+	if err = cursor.All(ctx, &results); err != nil || len(results) == 0 {
+		return facets, err
+	}
+
+	for _, v := range results[0].Artist {
+		facets.Artist = append(facets.Artist, models.FacetCount{Value: v.ID, Count: v.Count})
+	}
+
+	for _, v := range results[0].PriceBucket {
+		min, ok := v.ID.(float64)
+		if !ok {
+			continue
+		}
+
+		bucket := models.PriceBucket{Min: min, Count: v.Count}
+
+		if i := sort.SearchFloat64s(boundaries, min); i+1 < len(boundaries) {
+			max := boundaries[i+1]
+			bucket.Max = &max
+		}
+
+		facets.PriceBucket = append(facets.PriceBucket, bucket)
+	}
+
+	for _, v := range results[0].Year {
+		facets.Year = append(facets.Year, models.YearCount{Year: v.ID, Count: v.Count})
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+// serveFile answers with a GridFS file. http.ServeContent takes care of
+// Range, If-None-Match and If-Modified-Since, Cache-Control is up to the caller.
+func serveFile(c *gin.Context, bucket *gridfs.Bucket, id primitive.ObjectID, size int64, contentType, etag string, modified time.Time) {
+	file := &gridfsReader{bucket: bucket, id: id, size: size}
+	defer file.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Header("ETag", etag)
+
+	http.ServeContent(c.Writer, c.Request, "", modified, file)
+}
+
+// gridfsReader seeks in a GridFS file by reopening the download at the
+// wanted offset, so range requests only read the chunks they need
+type gridfsReader struct {
+	bucket *gridfs.Bucket
+	id     primitive.ObjectID
+	size   int64
+	offset int64
+	stream *gridfs.DownloadStream
+}
+
+func (r *gridfsReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.stream == nil {
+		stream, err := r.bucket.OpenDownloadStream(r.id)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err = stream.Skip(r.offset); err != nil {
+			stream.Close()
+			return 0, err
+		}
+
+		r.stream = stream
+	}
+
+	n, err := r.stream.Read(p)
+	r.offset += int64(n)
+
+	return n, err
+}
+
+func (r *gridfsReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+
+	if offset < 0 {
+		return 0, errors.New("seek before the start of the file")
+	}
+
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+
+	return offset, nil
+}
+
+func (r *gridfsReader) Close() error {
+	if r.stream == nil {
+		return nil
+	}
+
+	err := r.stream.Close()
+	r.stream = nil
+
+	return err
+}
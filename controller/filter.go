@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// albumFilter builds the mongo filter for the query parameters shared by the
+// album list and every endpoint that works on a filtered part of the catalog
+func albumFilter(c *gin.Context) (bson.M, error) {
+	filter := bson.M{}
+
+	if title := c.Query("title"); title != "" {
+		filter["title"] = bson.M{"$regex": regexp.QuoteMeta(title), "$options": "i"}
+	}
+
+	if artist := c.Query("artist"); artist != "" {
+		filter["artist"] = bson.M{"$regex": "^" + regexp.QuoteMeta(artist) + "$", "$options": "i"}
+	}
+
+	price := bson.M{}
+
+	for param, op := range map[string]string{"min_price": "$gte", "max_price": "$lte"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("invalid " + param)
+		}
+
+		price[op] = n
+	}
+
+	if len(price) > 0 {
+		filter["price"] = price
+	}
+
+	if genre := c.Query("genre"); genre != "" {
+		id, err := primitive.ObjectIDFromHex(genre)
+		if err != nil {
+			return nil, errors.New("invalid genre")
+		}
+
+		ids, err := genreSubtree(c, id)
+		if err != nil {
+			return nil, errors.New("unknown genre")
+		}
+
+		filter["genres"] = bson.M{"$in": ids}
+	}
+
+	if tags := c.Query("tag"); tags != "" {
+		filter["tags"] = bson.M{"$all": normalizeTags(strings.Split(tags, ","))}
+	}
+
+	if label := c.Query("label"); label != "" {
+		id, err := primitive.ObjectIDFromHex(label)
+		if err != nil {
+			return nil, errors.New("invalid label")
+		}
+
+		filter["label_id"] = id
+	}
+
+	if format := c.Query("format"); format != "" {
+		filter["format"] = strings.ToLower(format)
+	}
+
+	if country := c.Query("country"); country != "" {
+		filter["country"] = strings.ToUpper(country)
+	}
+
+	if catalogNumber := c.Query("catalog_number"); catalogNumber != "" {
+		filter["catalog_number"] = strings.TrimSpace(catalogNumber)
+	}
+
+	if barcode := c.Query("barcode"); barcode != "" {
+		filter["barcode"] = normalizeBarcode(barcode)
+	}
+
+	if lowStock := c.Query("low_stock"); lowStock != "" {
+		low, err := strconv.ParseBool(lowStock)
+		if err != nil {
+			return nil, errors.New("invalid low_stock")
+		}
+
+		filter["inventory.low_stock"] = low
+	}
+
+	released := bson.M{}
+
+	// release dates are stored as 2006-01-02, so they compare as strings
+	for param, op := range map[string]string{"released_from": "$gte", "released_to": "$lte"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return nil, errors.New("invalid " + param)
+		}
+
+		released[op] = value
+	}
+
+	if len(released) > 0 {
+		filter["release_date"] = released
+	}
+
+	return filter, nil
+}
+
+// albumSort is the order of ?sort, by ID unless it asks for the best rated
+// albums first
+func albumSort(c *gin.Context) (bson.D, error) {
+	switch c.Query("sort") {
+	case "":
+		return bson.D{{Key: "_id", Value: 1}}, nil
+	case "rating":
+		return bson.D{{Key: "rating.average", Value: -1}, {Key: "rating.count", Value: -1}, {Key: "_id", Value: 1}}, nil
+	}
+
+	return nil, errors.New("invalid sort")
+}
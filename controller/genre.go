@@ -0,0 +1,421 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"rest/database"
+	"rest/middlewares"
+	"rest/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errGenreNotFound = errors.New("genre not found")
+
+// GetGenres godoc
+// @Summary      Get genres
+// @Description  get all genres sorted by name, or the children of one genre
+// @Tags         genres
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        parent  query     string  false  "Only the direct children of this genre, root for the top level ones"
+// @Success      200  {array}   models.Genre
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/genres [get]
+func GetGenres(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	filter := bson.M{}
+
+	switch parent := c.Query("parent"); parent {
+	case "":
+	case "root":
+		filter["parent_id"] = bson.M{"$exists": false}
+	default:
+		id, err := primitive.ObjectIDFromHex(parent)
+
+		if err != nil {
+			respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid parent"})
+			return
+		}
+
+		filter["parent_id"] = id
+	}
+
+	opts := options.Find().SetSort(bson.M{"name": 1}).SetCollation(database.NameCollation)
+
+	cursor, err := genresCollection.Find(c, filter, opts)
+
+	if err != nil {
+		log.Println("genres failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the genres"})
+		return
+	}
+
+	genres := []models.Genre{}
+
+	if err = cursor.All(c, &genres); err != nil {
+		log.Println("genres failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the genres"})
+		return
+	}
+
+	respond(c, http.StatusOK, genres)
+}
+
+// GetGenreByID godoc
+// @Summary      Get a genre
+// @Description  get genre by ID
+// @Tags         genres
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Genre ID"
+// @Success      200  {object}  models.Genre
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/genres/{id} [get]
+func GetGenreByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	genre, err := findGenre(c, id)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "genre not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, genre)
+}
+
+// PostGenre godoc
+// @Summary      Add a genre
+// @Description  add a genre, at the top level or under a parent genre
+// @Tags         genres
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        genre  body      models.AddGenre  true  "Add Genre"
+// @Success      200	{object}  models.Genre
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/genres [post]
+func PostGenre(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var add models.AddGenre
+
+	if err := c.ShouldBindWith(&add, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	genre := models.Genre{ID: primitive.NewObjectID(), Name: strings.TrimSpace(add.Name), Ancestors: []primitive.ObjectID{}}
+
+	if add.ParentID != "" {
+		parentID, _ := primitive.ObjectIDFromHex(add.ParentID)
+
+		parent, err := findGenre(c, parentID)
+
+		if err != nil {
+			respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "parent genre not found"})
+			return
+		}
+
+		genre.ParentID = parent.ID
+		genre.Ancestors = append(parent.Ancestors, parent.ID)
+	}
+
+	if validationErr := validate.Struct(genre); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	genre.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	genre.Updated_at = genre.Created_at
+
+	_, err := genresCollection.InsertOne(c, genre)
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "genre already exists"})
+		return
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "Genre was not created"})
+		return
+	}
+
+	respond(c, http.StatusOK, genre)
+}
+
+// UpdateGenre godoc
+// @Summary      Update a genre
+// @Description  rename a genre or move it under another parent, its subtree moves along. An empty parent_id moves it to the top level.
+// @Tags         genres
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string           true  "Genre ID"
+// @Param        genre  body      models.AddGenre  true  "Update Genre"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Failure      415      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
+// @Router       /v1/genres/{id} [patch]
+func UpdateGenre(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	genre, err := findGenre(c, id)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "genre not found"})
+		return
+	}
+
+	// a missing parent_id keeps the genre where it is
+	body := struct {
+		Name     string  `json:"name" xml:"name"`
+		ParentID *string `json:"parent_id" xml:"parent_id"`
+	}{Name: genre.Name}
+
+	if err = c.ShouldBindWith(&body, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	genre.Name = strings.TrimSpace(body.Name)
+	moved := false
+
+	if body.ParentID != nil {
+		parentID, _ := primitive.ObjectIDFromHex(*body.ParentID)
+		moved = parentID != genre.ParentID
+
+		if moved && !parentID.IsZero() {
+			parent, err := findGenre(c, parentID)
+
+			if err != nil {
+				respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "parent genre not found"})
+				return
+			}
+
+			if parent.ID == id || containsID(parent.Ancestors, id) {
+				respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "a genre can't move under itself"})
+				return
+			}
+
+			genre.ParentID = parent.ID
+			genre.Ancestors = append(parent.Ancestors, parent.ID)
+		} else if moved {
+			genre.ParentID = primitive.NilObjectID
+			genre.Ancestors = []primitive.ObjectID{}
+		}
+	}
+
+	if validationErr := validate.Struct(genre); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	genre.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	err = inTransaction(c, func(sc mongo.SessionContext) error {
+		update := bson.M{"$set": genre}
+		if genre.ParentID.IsZero() {
+			update["$unset"] = bson.M{"parent_id": ""}
+		}
+
+		if _, err := genresCollection.UpdateByID(sc, id, update); err != nil || !moved {
+			return err
+		}
+
+		// the descendants keep their path below the genre and take its new path above it
+		_, err := genresCollection.UpdateMany(sc, bson.M{"ancestors": id}, bson.A{
+			bson.M{"$set": bson.M{"ancestors": bson.M{"$concatArrays": bson.A{
+				genre.Ancestors,
+				bson.M{"$slice": bson.A{"$ancestors", bson.M{"$indexOfArray": bson.A{"$ancestors", id}}, maxGenreDepth}},
+			}}}},
+		})
+
+		return err
+	})
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "genre already exists"})
+		return
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not update the genre"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the genre"})
+}
+
+// DeleteGenreByID godoc
+// @Summary      Delete a genre
+// @Description  delete a genre that has no sub-genres and no albums
+// @Tags         genres
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Genre ID"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Router       /v1/genres/{id} [delete]
+func DeleteGenreByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	if n, _ := genresCollection.CountDocuments(c, bson.M{"parent_id": id}); n > 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "genre still has sub-genres"})
+		return
+	}
+
+	if n, _ := albumsCollection.CountDocuments(c, bson.M{"genres": id}); n > 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "genre still has albums"})
+		return
+	}
+
+	res, _ := genresCollection.DeleteOne(c, bson.M{"_id": id})
+
+	if res.DeletedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "genre not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the genre"})
+}
+
+// deeper genre trees are not expected, it bounds the ancestors kept on a move
+const maxGenreDepth = 100
+
+func findGenre(ctx context.Context, id primitive.ObjectID) (models.Genre, error) {
+	var genre models.Genre
+
+	err := genresCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&genre)
+
+	return genre, err
+}
+
+// genreSubtree returns the id of the genre and of all the genres below it
+func genreSubtree(ctx context.Context, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if _, err := findGenre(ctx, id); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errGenreNotFound
+		}
+		return nil, err
+	}
+
+	cursor, err := genresCollection.Find(ctx, bson.M{"ancestors": id}, options.Find().SetProjection(bson.M{"_id": 1}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var descendants []models.Genre
+
+	if err = cursor.All(ctx, &descendants); err != nil {
+		return nil, err
+	}
+
+	ids := []primitive.ObjectID{id}
+	for _, genre := range descendants {
+		ids = append(ids, genre.ID)
+	}
+
+	return ids, nil
+}
+
+// checkGenres makes sure every genre of an album exists
+func checkGenres(ctx context.Context, ids []primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	n, err := genresCollection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}})
+
+	if err != nil {
+		return err
+	}
+
+	if int(n) != len(ids) {
+		return errGenreNotFound
+	}
+
+	return nil
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseObjectIDs parses hex ids, duplicates are dropped
+func parseObjectIDs(hexes []string) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+
+	for _, hex := range hexes {
+		id, err := primitive.ObjectIDFromHex(hex)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !containsID(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,136 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGenreAndTagRoutes(t *testing.T) {
+
+	var jazz, bebop models.Genre
+	json.Unmarshal(request("POST", "/genres", `{"name": "Genre Test Jazz"}`, admin).Body.Bytes(), &jazz)
+	json.Unmarshal(request("POST", "/genres", `{"name": "Genre Test Bebop", "parent_id": "`+jazz.ID.Hex()+`"}`, admin).Body.Bytes(), &bebop)
+
+	assert.Equal(t, []primitive.ObjectID{jazz.ID}, bebop.Ancestors)
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{
+		"title": "Genre album",
+		"artist": "Me Owais",
+		"price": 10,
+		"genres": ["`+bebop.ID.Hex()+`"],
+		"tags": [" Genre-Test-Live ", "genre-test-live", "genre-test-mono"]
+	}`, admin).Body.Bytes(), &postRes)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		response string
+		albums   int
+		status   int
+	}{
+		{
+			name:   "list the albums of a genre and its sub-genres",
+			method: "GET",
+			path:   "/albums?genre=" + jazz.ID.Hex(),
+			albums: 1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "list the albums carrying tags",
+			method: "GET",
+			path:   "/albums?tag=GENRE-TEST-LIVE,genre-test-mono",
+			albums: 1,
+			status: http.StatusOK,
+		},
+		{
+			name:     "try to list the albums of an unknown genre",
+			method:   "GET",
+			path:     "/albums?genre=000000000000000000000001",
+			response: `{"error":"unknown genre"}`,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "try to create a genre twice under the same parent",
+			method:   "POST",
+			path:     "/genres",
+			body:     `{"name": "genre test bebop", "parent_id": "` + jazz.ID.Hex() + `"}`,
+			response: `{"error":"genre already exists"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "try to move a genre under its own sub-genre",
+			method:   "PATCH",
+			path:     "/genres/" + jazz.ID.Hex(),
+			body:     `{"parent_id": "` + bebop.ID.Hex() + `"}`,
+			response: `{"error":"a genre can't move under itself"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to delete a genre that has sub-genres",
+			method:   "DELETE",
+			path:     "/genres/" + jazz.ID.Hex(),
+			response: `{"error":"genre still has sub-genres"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "merge tags",
+			method:   "POST",
+			path:     "/tags:merge",
+			body:     `{"from": ["genre-test-live", "genre-test-mono"], "to": "Genre-Test-Concert"}`,
+			response: `{"albums":1}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:   "list the albums carrying the merged tag",
+			method: "GET",
+			path:   "/albums?tag=genre-test-concert",
+			albums: 1,
+			status: http.StatusOK,
+		},
+		{
+			name:     "delete the album",
+			method:   "DELETE",
+			path:     "/albums/" + postRes.InsertedID,
+			response: `{"message":"successfully deleted the album"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "delete a genre",
+			method:   "DELETE",
+			path:     "/genres/" + bebop.ID.Hex(),
+			response: `{"message":"successfully deleted the genre"}`,
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, admin)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+
+			if tc.albums > 0 {
+				var albums []models.Album
+				json.Unmarshal(w.Body.Bytes(), &albums)
+
+				if assert.Len(t, albums, tc.albums) {
+					assert.Equal(t, postRes.InsertedID, albums[0].ID.Hex())
+				}
+			}
+		})
+	}
+
+	request("DELETE", "/genres/"+jazz.ID.Hex(), "", admin)
+}
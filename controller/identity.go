@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"crypto/rand"
+	"log"
+	"net/http"
+	"net/url"
+	"rest/middlewares"
+	"rest/models"
+	"rest/signing"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The API has no accounts of its own: the storefront authenticates its users
+// and forwards their ID along with a token signed for it, anonymous visitors
+// send a random session ID they keep client-side.
+const (
+	userHeader      = "X-User-ID"
+	userTokenHeader = "X-User-Token"
+	sessionHeader   = "X-Session-ID"
+)
+
+// how long a user token works, USER_TOKEN_TTL overrides it
+var userTokenTTL = 24 * time.Hour
+
+var userSigner *signing.Signer
+
+// initIdentity sets up the signing of user tokens. Without USER_TOKEN_KEY a
+// random key is used, tokens then stop working when the server restarts.
+func initIdentity() {
+	key := []byte(middlewares.DotEnvVariable("USER_TOKEN_KEY"))
+
+	if len(key) == 0 {
+		log.Println("USER_TOKEN_KEY is not set, user tokens only work until the server restarts")
+
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	userSigner = signing.New(key)
+
+	if ttl := middlewares.DotEnvVariable("USER_TOKEN_TTL"); ttl != "" {
+		var err error
+		if userTokenTTL, err = time.ParseDuration(ttl); err != nil || userTokenTTL <= 0 {
+			log.Fatal("USER_TOKEN_TTL: invalid duration ", ttl)
+		}
+	}
+}
+
+// PostUserToken godoc
+// @Summary      Issue a user token
+// @Description  sign a token for a user the storefront authenticated, requests then name the user with the X-User-ID and X-User-Token headers. The storefront may as well sign the tokens itself with USER_TOKEN_KEY.
+// @Tags         users
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  models.UserToken
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/users/{id}/token [post]
+func PostUserToken(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	user := strings.TrimSpace(c.Param("id"))
+
+	if validUserID(user) != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid user id"})
+		return
+	}
+
+	expires := time.Now().Add(userTokenTTL).Truncate(time.Second)
+
+	respond(c, http.StatusOK, models.UserToken{
+		UserID:     user,
+		Token:      userSigner.Sign(userTokenPath(user), expires).Encode(),
+		Expires_at: expires,
+	})
+}
+
+// currentUser reads the user ID of the request, empty for anonymous visitors.
+// The ID only counts with a token signed for it.
+func currentUser(c *gin.Context) (string, bool) {
+	user := strings.TrimSpace(c.GetHeader(userHeader))
+
+	if user == "" {
+		return "", true
+	}
+
+	if validUserID(user) != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid " + userHeader})
+		return "", false
+	}
+
+	token, err := url.ParseQuery(c.GetHeader(userTokenHeader))
+
+	if err == nil {
+		err = userSigner.Verify(userTokenPath(user), token, time.Now())
+	}
+
+	if err != nil {
+		respond(c, http.StatusUnauthorized, models.ErrorMessage{Error: "invalid " + userTokenHeader})
+		return "", false
+	}
+
+	return user, true
+}
+
+// currentSession reads the anonymous session ID of the request
+func currentSession(c *gin.Context) (string, bool) {
+	session := strings.TrimSpace(c.GetHeader(sessionHeader))
+
+	if session != "" && validate.Var(session, "min=16,max=64,printascii") != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid " + sessionHeader})
+		return "", false
+	}
+
+	return session, true
+}
+
+// requireUser answers 401 unless the request comes from a user
+func requireUser(c *gin.Context) (string, bool) {
+	user, ok := currentUser(c)
+
+	if ok && user == "" {
+		respond(c, http.StatusUnauthorized, models.ErrorMessage{Error: userHeader + " header required"})
+		return "", false
+	}
+
+	return user, ok
+}
+
+func validUserID(user string) error {
+	return validate.Var(user, "max=64,printascii")
+}
+
+// userTokenPath is what the token of a user signs
+func userTokenPath(user string) string {
+	return "users/" + user
+}
@@ -0,0 +1,103 @@
+package controller_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentity(t *testing.T) {
+
+	const user = "identity-test-user"
+
+	signedIn := asUser(t, user)
+	other := asUser(t, "identity-test-other")
+
+	tampered := strings.Replace(signedIn["X-User-Token"], "signature=", "signature=x", 1)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:     "try to get a token without the admin token",
+			method:   "POST",
+			path:     "/users/" + user + "/token",
+			response: `{"message":"wrong token"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to get a wishlist with a spoofed user header",
+			method:   "GET",
+			path:     "/wishlist",
+			headers:  map[string]string{"X-User-ID": user},
+			response: `{"error":"invalid X-User-Token"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to get a wishlist with the token of another user",
+			method:   "GET",
+			path:     "/wishlist",
+			headers:  map[string]string{"X-User-ID": user, "X-User-Token": other["X-User-Token"]},
+			response: `{"error":"invalid X-User-Token"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to get a wishlist with a tampered token",
+			method:   "GET",
+			path:     "/wishlist",
+			headers:  map[string]string{"X-User-ID": user, "X-User-Token": tampered},
+			response: `{"error":"invalid X-User-Token"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to get a cart with a spoofed user header",
+			method:   "GET",
+			path:     "/cart",
+			headers:  map[string]string{"X-User-ID": user, "X-Session-ID": "identity-test-session-0001"},
+			response: `{"error":"invalid X-User-Token"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to list orders with a spoofed user header",
+			method:   "GET",
+			path:     "/orders",
+			headers:  map[string]string{"X-User-ID": user},
+			response: `{"error":"invalid X-User-Token"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to read notifications with a spoofed user header",
+			method:   "GET",
+			path:     "/notifications",
+			headers:  map[string]string{"X-User-ID": user},
+			response: `{"error":"invalid X-User-Token"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:    "get a wishlist with a signed token",
+			method:  "GET",
+			path:    "/wishlist",
+			headers: signedIn,
+			status:  http.StatusOK,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, "", tc.headers)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+}
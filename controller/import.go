@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"rest/importer"
+	"rest/middlewares"
+	"rest/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errImportRunning = errors.New("another import is running, try again once it is done")
+
+// how long the import lock outlives a process that died holding it
+const importLockTTL = time.Minute
+
+// NewAlbumImporter returns an importer writing to the albums collection,
+// it is shared by the import endpoint and the import subcommand
+func NewAlbumImporter(progress func(models.ImportProgress)) *importer.Importer {
+	return &importer.Importer{
+		Collection: albumsCollection,
+		Validate:   validate,
+		Progress:   progress,
+		Currency:   defaultCurrency,
+		Artist: func(ctx context.Context, name string) (primitive.ObjectID, string, error) {
+			artist, err := artistByName(ctx, name)
+			return artist.ID, artist.Name, err
+		},
+		PriceChanged: func(ctx context.Context, album models.Album, previous models.Amount, currency string) {
+			priceChanged(ctx, album, previous, currency, models.PriceSourceImport)
+		},
+	}
+}
+
+// LockImport makes sure a single import writes albums at a time, across the
+// servers and the import subcommand. Imports match albums on their (title,
+// artist) pair, which isn't unique since the API accepts albums sharing it,
+// so two imports upserting the same new album would both insert it. The lock
+// is kept alive until unlock is called.
+func LockImport(ctx context.Context) (unlock func(), err error) {
+	owner := primitive.NewObjectID()
+
+	claim := func() error {
+		now := time.Now()
+
+		// the upsert only matches a lock that is ours or expired, otherwise it
+		// inserts a second one and runs into the _id
+		_, err := locksCollection.UpdateOne(ctx,
+			bson.M{"_id": "album_import", "$or": bson.A{bson.M{"owner": owner}, bson.M{"expires_at": bson.M{"$lte": now}}}},
+			bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(importLockTTL)}},
+			options.Update().SetUpsert(true))
+
+		if mongo.IsDuplicateKeyError(err) {
+			return errImportRunning
+		}
+
+		return err
+	}
+
+	if err := claim(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(importLockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := claim(); err != nil {
+					log.Println("keeping the import lock failed:", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+
+		if _, err := locksCollection.DeleteOne(context.Background(), bson.M{"_id": "album_import", "owner": owner}); err != nil {
+			log.Println("releasing the import lock failed:", err)
+		}
+	}, nil
+}
+
+// ImportAlbums godoc
+// @Summary      Import albums from a file
+// @Description  upsert albums by (title, artist) from a CSV or NDJSON upload, sent as the "file" form field or as the raw body. One import runs at a time, dry runs aside.
+// @Tags         albums
+// @Accept       mpfd,text/csv,application/x-ndjson
+// @Produce      json,application/x-ndjson
+// @Param        file      formData  file    false  "CSV or NDJSON file"
+// @Param        format    query     string  false  "csv or ndjson, guessed from the file name when omitted"
+// @Param        map       query     string  false  "column mapping, e.g. title=Album,artist=Band,price=Cost"
+// @Param        dry_run   query     bool    false  "only validate the rows"
+// @Param        progress  query     bool    false  "stream progress as NDJSON before the report"
+// @Success      200	{object}  models.ImportReport
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums:import [post]
+func ImportAlbums(c *gin.Context) {
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	mapping, err := importer.ParseMapping(c.Query("map"))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	progress, _ := strconv.ParseBool(c.Query("progress"))
+
+	var body io.Reader = c.Request.Body
+	name := c.Query("format")
+
+	if file, header, err := c.Request.FormFile("file"); err == nil {
+		defer file.Close()
+
+		body = file
+		if name == "" {
+			name = header.Filename
+		}
+	} else if name == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			name = "csv"
+		case "application/x-ndjson":
+			name = "ndjson"
+		}
+	}
+
+	format, err := importer.ParseFormat(name)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	opts := importer.Options{Format: format, Mapping: mapping, DryRun: dryRun}
+
+	// dry runs write nothing, they don't need the lock
+	if !dryRun {
+		unlock, err := LockImport(ctx)
+
+		if err == errImportRunning {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		defer unlock()
+	}
+
+	if !progress {
+		report, err := NewAlbumImporter(nil).Run(ctx, body, opts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	// stream one line per processed batch, followed by the report or the error
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+
+	report, err := NewAlbumImporter(func(p models.ImportProgress) {
+		enc.Encode(gin.H{"progress": p})
+		c.Writer.Flush()
+	}).Run(ctx, body, opts)
+
+	if err != nil {
+		enc.Encode(gin.H{"error": err.Error()})
+		return
+	}
+
+	enc.Encode(gin.H{"report": report})
+}
@@ -0,0 +1,632 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errNotEnoughStock      = errors.New("not enough stock")
+	errReservationNotFound = errors.New("reservation not found")
+	errReservationClosed   = errors.New("the reservation is no longer pending")
+	errReservationExpired  = errors.New("the reservation expired")
+)
+
+// how long a reservation holds stock
+var reservationTTL = 15 * time.Minute
+
+// how often expired reservations give their stock back
+const reservationSweepInterval = time.Minute
+
+func initInventory() {
+	if ttl := middlewares.DotEnvVariable("RESERVATION_TTL"); ttl != "" {
+		var err error
+		if reservationTTL, err = time.ParseDuration(ttl); err != nil || reservationTTL <= 0 {
+			log.Fatal("RESERVATION_TTL: invalid duration ", ttl)
+		}
+	}
+}
+
+// GetAlbumInventory godoc
+// @Summary      Get the stock of an album
+// @Description  get the stock on hand, reserved and available of an album
+// @Tags         inventory
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Album ID"
+// @Success      200  {object}  models.Inventory
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/inventory [get]
+func GetAlbumInventory(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	if album.Inventory == nil {
+		album.Inventory = &models.Inventory{}
+	}
+
+	respond(c, http.StatusOK, album.Inventory)
+}
+
+// UpdateAlbumInventory godoc
+// @Summary      Set the low stock threshold of an album
+// @Description  albums are flagged low_stock once their available stock drops to the threshold, 0 turns the flag off
+// @Tags         inventory
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id        path      string                    true  "Album ID"
+// @Param        settings  body      models.InventorySettings  true  "Inventory Settings"
+// @Success      200  {object}  models.Inventory
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/inventory [patch]
+func UpdateAlbumInventory(c *gin.Context) {
+	var settings models.InventorySettings
+
+	if !bindInventoryRequest(c, &settings) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	inventory, err := changeInventory(c, bson.M{"_id": id}, 0, 0, settings.LowStockThreshold)
+
+	if err == mongo.ErrNoDocuments {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not update the inventory"})
+		return
+	}
+
+	respond(c, http.StatusOK, inventory)
+}
+
+// AdjustAlbumInventory godoc
+// @Summary      Adjust the stock of an album
+// @Description  add stock on hand, or remove it with a negative quantity. Reserved stock can't be removed.
+// @Tags         inventory
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id          path      string                  true  "Album ID"
+// @Param        adjustment  body      models.AdjustInventory  true  "Adjustment"
+// @Success      200  {object}  models.Inventory
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/inventory/adjustments [post]
+func AdjustAlbumInventory(c *gin.Context) {
+	var adjustment models.AdjustInventory
+
+	if !bindInventoryRequest(c, &adjustment) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	filter := bson.M{"_id": id}
+	if adjustment.Quantity < 0 {
+		filter["inventory.available"] = bson.M{"$gte": -adjustment.Quantity}
+	}
+
+	var inventory models.Inventory
+
+	err := inTransaction(c, func(sc mongo.SessionContext) error {
+		var err error
+
+		if inventory, err = changeInventory(sc, filter, adjustment.Quantity, 0, nil); err != nil {
+			return err
+		}
+
+		return recordMovement(sc, models.InventoryMovement{
+			AlbumID:     id,
+			Type:        models.MovementAdjustment,
+			OnHandDelta: adjustment.Quantity,
+			Reason:      adjustment.Reason,
+			Inventory:   inventory,
+		})
+	})
+
+	if err == mongo.ErrNoDocuments {
+		if n, _ := albumsCollection.CountDocuments(c, bson.M{"_id": id}); n > 0 {
+			respond(c, http.StatusConflict, models.ErrorMessage{Error: "not enough unreserved stock"})
+			return
+		}
+
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	if err != nil {
+		log.Println("inventory adjustment failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not adjust the inventory"})
+		return
+	}
+
+	respond(c, http.StatusOK, inventory)
+}
+
+// GetAlbumInventoryMovements godoc
+// @Summary      Get the inventory ledger of an album
+// @Description  get every change of the stock of an album, newest first
+// @Tags         inventory
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string  true   "Album ID"
+// @Param        page   query     int     false  "Page number, starting at 1"
+// @Param        limit  query     int     false  "Movements per page, at most 100"
+// @Success      200  {array}   models.InventoryMovement
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/inventory/movements [get]
+func GetAlbumInventoryMovements(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+	filter := bson.M{"album_id": id}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	if paginated {
+		total, err := movementsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("inventory movements failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the inventory movements"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	movements := []models.InventoryMovement{}
+
+	cursor, err := movementsCollection.Find(c, filter, opts)
+
+	if err == nil {
+		err = cursor.All(c, &movements)
+	}
+
+	if err != nil {
+		log.Println("inventory movements failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the inventory movements"})
+		return
+	}
+
+	respond(c, http.StatusOK, movements)
+}
+
+// PostAlbumReservation godoc
+// @Summary      Reserve stock
+// @Description  hold stock of an album until the reservation is committed or released, it is released by itself after RESERVATION_TTL
+// @Tags         inventory
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id           path      string                 true  "Album ID"
+// @Param        reservation  body      models.AddReservation  true  "Reservation"
+// @Success      200  {object}  models.Reservation
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/reservations [post]
+func PostAlbumReservation(c *gin.Context) {
+	var add models.AddReservation
+
+	if !bindInventoryRequest(c, &add) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	reservation, err := reserveStock(c, id, add.Quantity)
+
+	if err == errNotEnoughStock {
+		if n, _ := albumsCollection.CountDocuments(c, bson.M{"_id": id}); n == 0 {
+			respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+			return
+		}
+
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("reservation failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not reserve the stock"})
+		return
+	}
+
+	respond(c, http.StatusOK, reservation)
+}
+
+// GetReservation godoc
+// @Summary      Get a reservation
+// @Description  get reservation by ID
+// @Tags         inventory
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Reservation ID"
+// @Success      200  {object}  models.Reservation
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/reservations/{id} [get]
+func GetReservation(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var reservation models.Reservation
+
+	if err := reservationsCollection.FindOne(c, bson.M{"_id": id}).Decode(&reservation); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "reservation not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, reservation)
+}
+
+// CommitReservation godoc
+// @Summary      Commit a reservation
+// @Description  take the reserved stock out of the stock on hand, once the sale is done
+// @Tags         inventory
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Reservation ID"
+// @Success      200  {object}  models.Reservation
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/reservations/{id}/commit [post]
+func CommitReservation(c *gin.Context) {
+	closeReservationResponse(c, models.ReservationCommitted)
+}
+
+// ReleaseReservation godoc
+// @Summary      Release a reservation
+// @Description  give the reserved stock back
+// @Tags         inventory
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Reservation ID"
+// @Success      200  {object}  models.Reservation
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/reservations/{id}/release [post]
+func ReleaseReservation(c *gin.Context) {
+	closeReservationResponse(c, models.ReservationReleased)
+}
+
+func bindInventoryRequest(c *gin.Context, req interface{}) bool {
+	if !negotiate(c) {
+		return false
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return false
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return false
+	}
+
+	if err := c.ShouldBindWith(req, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return false
+	}
+
+	if validationErr := validate.Struct(req); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return false
+	}
+
+	return true
+}
+
+func closeReservationResponse(c *gin.Context, status string) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	reservation, err := closeReservation(c, id, status, time.Now())
+
+	switch err {
+	case nil:
+		respond(c, http.StatusOK, reservation)
+	case errReservationNotFound:
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: err.Error()})
+	case errReservationClosed, errReservationExpired:
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+	default:
+		log.Println("reservation change failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not change the reservation"})
+	}
+}
+
+// changeInventory moves the stock of the album matching filter in a single
+// conditional update and returns the new stock. The filter carries the
+// conditions, e.g. enough available stock, so concurrent changes can't
+// oversell. It returns mongo.ErrNoDocuments when nothing matched.
+func changeInventory(ctx context.Context, filter bson.M, onHand, reserved int, threshold *int) (models.Inventory, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	set := bson.M{
+		"inventory.on_hand":  bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$inventory.on_hand", 0}}, onHand}},
+		"inventory.reserved": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$inventory.reserved", 0}}, reserved}},
+		"updated_at":         now,
+	}
+
+	if threshold != nil {
+		set["inventory.low_stock_threshold"] = *threshold
+	} else {
+		set["inventory.low_stock_threshold"] = bson.M{"$ifNull": bson.A{"$inventory.low_stock_threshold", 0}}
+	}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: set}},
+		{{Key: "$set", Value: bson.M{
+			"inventory.available": bson.M{"$subtract": bson.A{"$inventory.on_hand", "$inventory.reserved"}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"inventory.low_stock": bson.M{"$and": bson.A{
+				bson.M{"$gt": bson.A{"$inventory.low_stock_threshold", 0}},
+				bson.M{"$lte": bson.A{"$inventory.available", "$inventory.low_stock_threshold"}},
+			}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"inventory": 1})
+
+	var album models.Album
+
+	if err := albumsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&album); err != nil {
+		return models.Inventory{}, err
+	}
+
+	return *album.Inventory, nil
+}
+
+func recordMovement(ctx context.Context, movement models.InventoryMovement) error {
+	movement.ID = primitive.NewObjectID()
+	movement.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	_, err := movementsCollection.InsertOne(ctx, movement)
+	return err
+}
+
+// reserveStock holds quantity of the album for reservationTTL
+func reserveStock(ctx context.Context, albumID primitive.ObjectID, quantity int) (models.Reservation, error) {
+	var reservation models.Reservation
+
+	err := inTransaction(ctx, func(sc mongo.SessionContext) error {
+		var err error
+		reservation, err = holdStock(sc, albumID, quantity)
+		return err
+	})
+
+	return reservation, err
+}
+
+// holdStock is reserveStock within the transaction of sc
+func holdStock(sc mongo.SessionContext, albumID primitive.ObjectID, quantity int) (models.Reservation, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	reservation := models.Reservation{
+		ID:         primitive.NewObjectID(),
+		AlbumID:    albumID,
+		Quantity:   quantity,
+		Status:     models.ReservationPending,
+		Expires_at: now.Add(reservationTTL),
+		Created_at: now,
+		Updated_at: now,
+	}
+
+	inventory, err := changeInventory(sc,
+		bson.M{"_id": albumID, "inventory.available": bson.M{"$gte": quantity}}, 0, quantity, nil)
+
+	if err == mongo.ErrNoDocuments {
+		return reservation, errNotEnoughStock
+	}
+
+	if err != nil {
+		return reservation, err
+	}
+
+	if _, err = reservationsCollection.InsertOne(sc, reservation); err != nil {
+		return reservation, err
+	}
+
+	return reservation, recordMovement(sc, models.InventoryMovement{
+		AlbumID:       albumID,
+		Type:          models.MovementReserve,
+		ReservedDelta: quantity,
+		ReservationID: reservation.ID,
+		Inventory:     inventory,
+	})
+}
+
+// closeReservation commits, releases or expires a pending reservation and
+// moves the stock it held accordingly
+func closeReservation(ctx context.Context, id primitive.ObjectID, status string, now time.Time) (models.Reservation, error) {
+	var reservation models.Reservation
+
+	err := inTransaction(ctx, func(sc mongo.SessionContext) error {
+		var err error
+		reservation, err = settleReservation(sc, id, status, now)
+		return err
+	})
+
+	return reservation, err
+}
+
+// settleReservation is closeReservation within the transaction of sc
+func settleReservation(sc mongo.SessionContext, id primitive.ObjectID, status string, now time.Time) (models.Reservation, error) {
+	now, _ = time.Parse(time.RFC3339, now.Format(time.RFC3339))
+
+	var reservation models.Reservation
+
+	if err := reservationsCollection.FindOne(sc, bson.M{"_id": id}).Decode(&reservation); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return reservation, errReservationNotFound
+		}
+		return reservation, err
+	}
+
+	if reservation.Status != models.ReservationPending {
+		return reservation, errReservationClosed
+	}
+
+	if status == models.ReservationCommitted && !now.Before(reservation.Expires_at) {
+		return reservation, errReservationExpired
+	}
+
+	res, err := reservationsCollection.UpdateOne(sc,
+		bson.M{"_id": id, "status": models.ReservationPending},
+		bson.M{"$set": bson.M{"status": status, "updated_at": now}})
+
+	if err != nil {
+		return reservation, err
+	}
+
+	if res.ModifiedCount == 0 {
+		return reservation, errReservationClosed
+	}
+
+	reservation.Status = status
+	reservation.Updated_at = now
+
+	movement := models.InventoryMovement{
+		AlbumID:       reservation.AlbumID,
+		ReservedDelta: -reservation.Quantity,
+		ReservationID: reservation.ID,
+	}
+
+	switch status {
+	case models.ReservationCommitted:
+		movement.Type = models.MovementCommit
+		movement.OnHandDelta = -reservation.Quantity
+	case models.ReservationExpired:
+		movement.Type = models.MovementExpire
+	default:
+		movement.Type = models.MovementRelease
+	}
+
+	movement.Inventory, err = changeInventory(sc, bson.M{"_id": reservation.AlbumID},
+		movement.OnHandDelta, movement.ReservedDelta, nil)
+
+	if err == mongo.ErrNoDocuments {
+		// the album is gone, there is no stock to move
+		return reservation, nil
+	}
+
+	if err != nil {
+		return reservation, err
+	}
+
+	return reservation, recordMovement(sc, movement)
+}
+
+// StartReservationExpiry gives the stock of expired reservations back, and
+// cancels the orders that weren't paid in time, in the background until ctx
+// is done
+func StartReservationExpiry(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(reservationSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := expireOrders(ctx, time.Now()); err != nil {
+				log.Println("order expiry:", err)
+			}
+
+			if err := expireReservations(ctx, time.Now()); err != nil {
+				log.Println("reservation expiry:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func expireReservations(ctx context.Context, now time.Time) error {
+	cursor, err := reservationsCollection.Find(ctx,
+		bson.M{"status": models.ReservationPending, "expires_at": bson.M{"$lte": now}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+
+	if err != nil {
+		return err
+	}
+
+	var expired []models.Reservation
+
+	if err = cursor.All(ctx, &expired); err != nil {
+		return err
+	}
+
+	for _, reservation := range expired {
+		_, err = closeReservation(ctx, reservation.ID, models.ReservationExpired, now)
+
+		// committed or released meanwhile
+		if err != nil && err != errReservationClosed {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInventoryRoutes(t *testing.T) {
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Stocked album", "artist": "Me Owais", "price": 20}`, admin).Body.Bytes(), &postRes)
+
+	albumPath := "/albums/" + postRes.InsertedID
+
+	request("PATCH", albumPath+"/inventory", `{"low_stock_threshold": 2}`, admin)
+	request("POST", albumPath+"/inventory/adjustments", `{"quantity": 5, "reason": "delivery"}`, admin)
+
+	var reservation models.Reservation
+	json.Unmarshal(request("POST", albumPath+"/reservations", `{"quantity": 3}`, admin).Body.Bytes(), &reservation)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		response string
+		status   int
+	}{
+		{
+			name:     "get the stock",
+			method:   "GET",
+			path:     albumPath + "/inventory",
+			response: `{"on_hand":5,"reserved":3,"available":2,"low_stock_threshold":2,"low_stock":true}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to reserve more than is available",
+			method:   "POST",
+			path:     albumPath + "/reservations",
+			body:     `{"quantity": 3}`,
+			response: `{"error":"not enough stock"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "try to remove reserved stock",
+			method:   "POST",
+			path:     albumPath + "/inventory/adjustments",
+			body:     `{"quantity": -3}`,
+			response: `{"error":"not enough unreserved stock"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:   "try to reserve nothing",
+			method: "POST",
+			path:   albumPath + "/reservations",
+			body:   `{"quantity": 0}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "commit the reservation",
+			method: "POST",
+			path:   "/reservations/" + reservation.ID.Hex() + "/commit",
+			status: http.StatusOK,
+		},
+		{
+			name:     "try to release a committed reservation",
+			method:   "POST",
+			path:     "/reservations/" + reservation.ID.Hex() + "/release",
+			response: `{"error":"the reservation is no longer pending"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "get the stock after the sale",
+			method:   "GET",
+			path:     albumPath + "/inventory",
+			response: `{"on_hand":2,"reserved":0,"available":2,"low_stock_threshold":2,"low_stock":true}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to reserve stock of a missing album",
+			method:   "POST",
+			path:     "/albums/000000000000000000000000/reservations",
+			body:     `{"quantity": 1}`,
+			response: `{"error":"album not found"}`,
+			status:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, admin)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var movements []models.InventoryMovement
+	json.Unmarshal(request("GET", albumPath+"/inventory/movements", "", admin).Body.Bytes(), &movements)
+
+	if assert.Len(t, movements, 3) {
+		assert.Equal(t, models.MovementCommit, movements[0].Type)
+		assert.Equal(t, -3, movements[0].OnHandDelta)
+		assert.Equal(t, models.MovementAdjustment, movements[2].Type)
+	}
+
+	assert.Equal(t, http.StatusOK, request("DELETE", albumPath, "", admin).Code)
+}
@@ -0,0 +1,314 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"rest/database"
+	"rest/middlewares"
+	"rest/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errLabelNotFound = errors.New("label not found")
+
+// GetLabels godoc
+// @Summary      Get all labels
+// @Description  get record labels sorted by name
+// @Tags         labels
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        name   query     string  false  "Name contains"
+// @Param        page   query     int     false  "Page number, starting at 1"
+// @Param        limit  query     int     false  "Labels per page, at most 100"
+// @Success      200  {array}   models.Label
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/labels [get]
+func GetLabels(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	filter := bson.M{}
+
+	if name := c.Query("name"); name != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(name), "$options": "i"}
+	}
+
+	opts := options.Find().SetSort(bson.M{"name": 1}).SetCollation(database.NameCollation)
+
+	if paginated {
+		total, err := labelsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("labels failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the labels"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	cursor, err := labelsCollection.Find(c, filter, opts)
+
+	if err != nil {
+		log.Println("labels failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the labels"})
+		return
+	}
+
+	labels := []models.Label{}
+
+	if err = cursor.All(c, &labels); err != nil {
+		log.Println("labels failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the labels"})
+		return
+	}
+
+	respond(c, http.StatusOK, labels)
+}
+
+// GetLabelByID godoc
+// @Summary      Get a label
+// @Description  get record label by ID
+// @Tags         labels
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Label ID"
+// @Success      200  {object}  models.Label
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/labels/{id} [get]
+func GetLabelByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var label models.Label
+
+	err := labelsCollection.FindOne(c, bson.M{"_id": id}).Decode(&label)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "label not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, label)
+}
+
+// PostLabel godoc
+// @Summary      Add a label
+// @Description  add record label by json, names are unique regardless of case
+// @Tags         labels
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        label  body      models.AddLabel  true  "Add Label"
+// @Success      200	{object}  models.Label
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/labels [post]
+func PostLabel(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var label models.Label
+
+	if err := c.ShouldBindWith(&label, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	prepareLabel(&label)
+
+	if validationErr := validate.Struct(label); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	label.ID = primitive.NewObjectID()
+	label.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	label.Updated_at = label.Created_at
+
+	_, err := labelsCollection.InsertOne(c, label)
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "label already exists"})
+		return
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "Label was not created"})
+		return
+	}
+
+	respond(c, http.StatusOK, label)
+}
+
+// UpdateLabel godoc
+// @Summary      Update a label
+// @Description  update record label by json
+// @Tags         labels
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string           true  "Label ID"
+// @Param        label  body      models.AddLabel  true  "Update Label"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Failure      415      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
+// @Router       /v1/labels/{id} [patch]
+func UpdateLabel(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var label models.Label
+
+	err := labelsCollection.FindOne(c, bson.M{"_id": id}).Decode(&label)
+
+	if err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "label not found"})
+		return
+	}
+
+	if err = c.ShouldBindWith(&label, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	label.ID = id
+	prepareLabel(&label)
+
+	if validationErr := validate.Struct(&label); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	label.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	res, err := labelsCollection.UpdateByID(c, id, bson.M{"$set": label})
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "label already exists"})
+		return
+	}
+
+	if err != nil || res.MatchedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "label not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the label"})
+}
+
+// DeleteLabelByID godoc
+// @Summary      Delete a label
+// @Description  delete a record label no album is released on
+// @Tags         labels
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Label ID"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Router       /v1/labels/{id} [delete]
+func DeleteLabelByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	albums, err := albumsCollection.CountDocuments(c, bson.M{"label_id": id})
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not delete the label"})
+		return
+	}
+
+	if albums > 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "label still has albums"})
+		return
+	}
+
+	res, _ := labelsCollection.DeleteOne(c, bson.M{"_id": id})
+
+	if res.DeletedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "label not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the label"})
+}
+
+func prepareLabel(label *models.Label) {
+	label.Name = strings.TrimSpace(label.Name)
+	label.Country = strings.ToUpper(strings.TrimSpace(label.Country))
+}
+
+// checkLabel makes sure the label an album refers to exists. Albums without
+// a label pass.
+func checkLabel(ctx context.Context, id primitive.ObjectID) error {
+	if id.IsZero() {
+		return nil
+	}
+
+	n, err := labelsCollection.CountDocuments(ctx, bson.M{"_id": id})
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return errLabelNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelRoutes(t *testing.T) {
+
+	var label models.Label
+	json.Unmarshal(request("POST", "/labels", `{"name": "Label Test Records", "country": "gb"}`, admin).Body.Bytes(), &label)
+
+	assert.Equal(t, "GB", label.Country)
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{
+		"title": "Label album",
+		"artist": "Me Owais",
+		"price": 10,
+		"label_id": "`+label.ID.Hex()+`",
+		"release_date": "1959-08-17",
+		"catalog_number": "LT-0001",
+		"barcode": "4 006381 33393 1",
+		"format": "Vinyl",
+		"country": "gb"
+	}`, admin).Body.Bytes(), &postRes)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		response string
+		albums   int
+		status   int
+	}{
+		{
+			name:   "list the albums of a label",
+			method: "GET",
+			path:   "/albums?label=" + label.ID.Hex() + "&format=vinyl&country=GB",
+			albums: 1,
+			status: http.StatusOK,
+		},
+		{
+			name:   "list the albums by barcode and release date",
+			method: "GET",
+			path:   "/albums?barcode=4006381333931&released_from=1959-01-01&released_to=1959-12-31",
+			albums: 1,
+			status: http.StatusOK,
+		},
+		{
+			name:     "try to filter on an invalid release date",
+			method:   "GET",
+			path:     "/albums?released_from=1959",
+			response: `{"error":"invalid released_from"}`,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:   "try to create an album with a wrong check digit",
+			method: "POST",
+			path:   "/albums",
+			body:   `{"title": "Label album", "artist": "Me Owais", "price": 10, "barcode": "4006381333932"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "try to create an album in an unknown format",
+			method: "POST",
+			path:   "/albums",
+			body:   `{"title": "Label album", "artist": "Me Owais", "price": 10, "format": "cassette"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to create an album on an unknown label",
+			method:   "POST",
+			path:     "/albums",
+			body:     `{"title": "Label album", "artist": "Me Owais", "price": 10, "label_id": "000000000000000000000001"}`,
+			response: `{"error":"label not found"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to create a label twice",
+			method:   "POST",
+			path:     "/labels",
+			body:     `{"name": "label test records"}`,
+			response: `{"error":"label already exists"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "try to delete a label that has albums",
+			method:   "DELETE",
+			path:     "/labels/" + label.ID.Hex(),
+			response: `{"error":"label still has albums"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "delete the album",
+			method:   "DELETE",
+			path:     "/albums/" + postRes.InsertedID,
+			response: `{"message":"successfully deleted the album"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "delete the label",
+			method:   "DELETE",
+			path:     "/labels/" + label.ID.Hex(),
+			response: `{"message":"successfully deleted the label"}`,
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, admin)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+
+			if tc.albums > 0 {
+				var albums []models.Album
+				json.Unmarshal(w.Body.Bytes(), &albums)
+
+				if assert.Len(t, albums, tc.albums) {
+					assert.Equal(t, postRes.InsertedID, albums[0].ID.Hex())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"net/http"
+	"rest/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+// response formats offered by the album handlers, the first one is the default
+var offeredFormats = []string{
+	binding.MIMEJSON,
+	binding.MIMEXML,
+	binding.MIMEXML2,
+	binding.MIMEYAML,
+	binding.MIMEMSGPACK,
+	binding.MIMEMSGPACK2,
+}
+
+// negotiate checks the Accept header up front, so nothing is written to the
+// database for a request whose response can't be rendered anyway
+func negotiate(c *gin.Context) bool {
+	if c.NegotiateFormat(offeredFormats...) == "" {
+		c.JSON(http.StatusNotAcceptable, models.ErrorMessage{Error: "not acceptable"})
+		return false
+	}
+
+	return true
+}
+
+// respond renders data in the format negotiated from the Accept header
+func respond(c *gin.Context, code int, data interface{}) {
+	switch c.NegotiateFormat(offeredFormats...) {
+	case binding.MIMEXML, binding.MIMEXML2:
+		c.XML(code, data)
+	case binding.MIMEYAML:
+		c.YAML(code, data)
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(code, render.MsgPack{Data: data})
+	default:
+		c.JSON(code, data)
+	}
+}
+
+// bodyBinding picks the binding for the request Content-Type, JSON when it is missing
+func bodyBinding(c *gin.Context) (binding.Binding, bool) {
+	switch c.ContentType() {
+	case "", binding.MIMEJSON:
+		return binding.JSON, true
+	case binding.MIMEXML, binding.MIMEXML2:
+		return binding.XML, true
+	case binding.MIMEYAML:
+		return binding.YAML, true
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		return binding.MsgPack, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"rest/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetNotifications godoc
+// @Summary      Get the notifications of the user
+// @Description  get the notifications of the user, newest first, like the price drops of the albums of their wishlist
+// @Tags         notifications
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true   "ID of the signed in user"
+// @Param        X-User-Token  header    string  true   "Token signed for the user, see /users/{id}/token"
+// @Param        unread        query     bool    false  "Only the notifications not read yet"
+// @Param        page          query     int     false  "Page number, starting at 1"
+// @Param        limit         query     int     false  "Notifications per page, at most 100"
+// @Success      200  {array}   models.Notification
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/notifications [get]
+func GetNotifications(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	filter := bson.M{"user_id": user}
+
+	if value := c.Query("unread"); value != "" {
+		unread, err := strconv.ParseBool(value)
+
+		if err != nil {
+			respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid unread"})
+			return
+		}
+
+		if unread {
+			filter["read"] = false
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	if paginated {
+		total, err := notificationsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("notifications failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the notifications"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	notifications := []models.Notification{}
+
+	cursor, err := notificationsCollection.Find(c, filter, opts)
+
+	if err == nil {
+		err = cursor.All(c, &notifications)
+	}
+
+	if err != nil {
+		log.Println("notifications failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the notifications"})
+		return
+	}
+
+	respond(c, http.StatusOK, notifications)
+}
+
+// ReadNotification godoc
+// @Summary      Mark a notification read
+// @Description  mark a notification of the user read
+// @Tags         notifications
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string  true  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string  true  "Notification ID"
+// @Success      200  {object}  models.SuccessMessage
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/notifications/{id}/read [post]
+func ReadNotification(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	res, err := notificationsCollection.UpdateOne(c, bson.M{"_id": id, "user_id": user}, bson.M{"$set": bson.M{"read": true}})
+
+	if err != nil {
+		log.Println("notification failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not mark the notification read"})
+		return
+	}
+
+	if res.MatchedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "notification not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully marked the notification read"})
+}
+
+// notifyPriceDrop tells the users who saved the album in their wishlist that
+// its price dropped from previous
+func notifyPriceDrop(ctx context.Context, album models.Album, previous models.Amount) error {
+	cursor, err := wishlistsCollection.Find(ctx, bson.M{"items.album_id": album.ID},
+		options.Find().SetProjection(bson.M{"user_id": 1}))
+
+	if err != nil {
+		return err
+	}
+
+	var wishlists []models.Wishlist
+
+	if err = cursor.All(ctx, &wishlists); err != nil || len(wishlists) == 0 {
+		return err
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	notifications := make([]interface{}, 0, len(wishlists))
+
+	for _, wishlist := range wishlists {
+		notifications = append(notifications, models.Notification{
+			ID:         primitive.NewObjectID(),
+			UserID:     wishlist.UserID,
+			Type:       models.NotificationPriceDrop,
+			AlbumID:    album.ID,
+			Title:      album.Title,
+			Previous:   previous,
+			Price:      album.Price,
+			Currency:   album.Currency,
+			Created_at: now,
+		})
+	}
+
+	_, err = notificationsCollection.InsertMany(ctx, notifications)
+	return err
+}
@@ -0,0 +1,821 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"rest/payment"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errOrderChanged = errors.New("the order changed meanwhile, try again")
+
+var paymentProvider payment.PaymentProvider
+
+// the statuses each order status can move to
+var orderTransitions = map[string][]string{
+	models.OrderPending:   {models.OrderPaid, models.OrderCancelled},
+	models.OrderPaid:      {models.OrderShipped, models.OrderRefunded},
+	models.OrderShipped:   {models.OrderDelivered, models.OrderRefunded},
+	models.OrderDelivered: {models.OrderRefunded},
+}
+
+func initOrders() {
+	switch provider := middlewares.DotEnvVariable("PAYMENT_PROVIDER"); provider {
+	case "", "fake":
+		paymentProvider = payment.NewFake()
+	default:
+		log.Fatal("PAYMENT_PROVIDER: unknown provider ", provider)
+	}
+}
+
+// PostOrder godoc
+// @Summary      Check out the cart
+// @Description  order the albums of the cart of the user at their current prices, with shipping to the address and its tax. The stock is held until the order is paid, or cancelled when it isn't paid within RESERVATION_TTL.
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string           true   "ID of the signed in user"
+// @Param        X-User-Token  header    string           true   "Token signed for the user, see /users/{id}/token"
+// @Param        currency      query     string           false  "ISO 4217 currency to pay in, the currency of the albums by default"
+// @Param        codes         query     string           false  "Comma separated promotion codes"
+// @Param        checkout      body      models.Checkout  false  "Where to ship the albums"
+// @Success      200  {object}  models.Order
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/orders [post]
+func PostOrder(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	currency, ok := requestedCurrency(c)
+
+	if !ok {
+		return
+	}
+
+	var checkout models.Checkout
+
+	// the body is optional, digital albums ship nowhere
+	if c.Request.ContentLength != 0 {
+		bodyFormat, ok := bodyBinding(c)
+
+		if !ok {
+			respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+			return
+		}
+
+		if err := c.ShouldBindWith(&checkout, bodyFormat); err != nil {
+			respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+			return
+		}
+
+		if checkout.Address != nil {
+			prepareAddress(checkout.Address)
+		}
+
+		if validationErr := validate.Struct(checkout); validationErr != nil {
+			respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+			return
+		}
+	}
+
+	cart, previous, _, err := loadCart(c, bson.M{"user_id": user})
+
+	if err != nil {
+		log.Println("checkout failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the cart"})
+		return
+	}
+
+	if len(cart.Items) == 0 {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "the cart is empty"})
+		return
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		ids = append(ids, item.AlbumID)
+	}
+
+	albums, err := findAlbums(c, ids)
+
+	if err != nil {
+		log.Println("checkout failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the cart"})
+		return
+	}
+
+	view, err := cartView(cart, albums, currency)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	items := make([]quoteItem, 0, len(view.Items))
+
+	for _, line := range view.Items {
+		if !line.Available {
+			respond(c, http.StatusConflict, models.ErrorMessage{Error: line.Problem + ": " + line.AlbumID.Hex()})
+			return
+		}
+
+		items = append(items, quoteItem{albumID: line.AlbumID, quantity: line.Quantity})
+	}
+
+	var codes []string
+	if query := c.Query("codes"); query != "" {
+		codes = strings.Split(query, ",")
+	}
+
+	quote, promotions, err := quotePrices(c, albums, items, view.Subtotal.Currency, codes, user)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if len(quote.Rejected) > 0 {
+		rejected := quote.Rejected[0]
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "code " + rejected.Code + ": " + rejected.Reason})
+		return
+	}
+
+	var destination *models.Destination
+
+	if checkout.Address != nil {
+		d := checkout.Address.Destination()
+		destination = &d
+	} else if parcelWeight(quote, albums) > 0 {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: errAddressRequired.Error()})
+		return
+	}
+
+	if err = addTaxAndShipping(&quote, albums, destination); err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	order := models.Order{
+		ID:              primitive.NewObjectID(),
+		UserID:          user,
+		Status:          models.OrderPending,
+		Items:           []models.OrderItem{},
+		Subtotal:        quote.Subtotal,
+		Discount:        quote.Discount,
+		Shipping:        quote.Shipping,
+		Tax:             quote.Tax,
+		Total:           quote.Total,
+		ShippingAddress: checkout.Address,
+		Promotions:      quote.Promotions,
+		Expires_at:      now.Add(reservationTTL),
+		History:         []models.OrderEvent{{Status: models.OrderPending, At: now}},
+		Created_at:      now,
+		Updated_at:      now,
+	}
+
+	for i, line := range quote.Items {
+		order.Items = append(order.Items, models.OrderItem{
+			AlbumID:  line.AlbumID,
+			Title:    view.Items[i].Title,
+			Artist:   view.Items[i].Artist,
+			Quantity: line.Quantity,
+			Price:    line.Price,
+			Discount: line.Discount,
+			Total:    line.Total,
+		})
+	}
+
+	err = inTransaction(c, func(sc mongo.SessionContext) error {
+		for i, item := range order.Items {
+			if albums[item.AlbumID].Inventory == nil {
+				continue
+			}
+
+			reservation, err := holdStock(sc, item.AlbumID, item.Quantity)
+
+			if err != nil {
+				return err
+			}
+
+			order.Items[i].ReservationID = reservation.ID
+		}
+
+		if err := redeemPromotions(sc, order, promotions); err != nil {
+			return err
+		}
+
+		if _, err := ordersCollection.InsertOne(sc, order); err != nil {
+			return err
+		}
+
+		res, err := cartsCollection.DeleteOne(sc, bson.M{"_id": cart.ID, "items": previous})
+
+		if err != nil {
+			return err
+		}
+
+		if res.DeletedCount == 0 {
+			return errCartChanged
+		}
+
+		return nil
+	})
+
+	if err == errNotEnoughStock || err == errCartChanged || err == errPromotionUsedUp {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("checkout failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not place the order"})
+		return
+	}
+
+	respond(c, http.StatusOK, order)
+}
+
+// GetOrders godoc
+// @Summary      Get the orders of the user
+// @Description  get the orders of the user, newest first
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true   "ID of the signed in user"
+// @Param        X-User-Token  header    string  true   "Token signed for the user, see /users/{id}/token"
+// @Param        page          query     int     false  "Page number, starting at 1"
+// @Param        limit         query     int     false  "Orders per page, at most 100"
+// @Success      200  {array}   models.Order
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/orders [get]
+func GetOrders(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	filter := bson.M{"user_id": user}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	if paginated {
+		total, err := ordersCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("orders failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the orders"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	orders := []models.Order{}
+
+	cursor, err := ordersCollection.Find(c, filter, opts)
+
+	if err == nil {
+		err = cursor.All(c, &orders)
+	}
+
+	if err != nil {
+		log.Println("orders failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the orders"})
+		return
+	}
+
+	respond(c, http.StatusOK, orders)
+}
+
+// GetOrderByID godoc
+// @Summary      Get an order
+// @Description  get an order of the user by ID, any order with an admin token
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  false  "ID of the signed in user"
+// @Param        X-User-Token  header    string  false  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string  true   "Order ID"
+// @Success      200  {object}  models.Order
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/orders/{id} [get]
+func GetOrderByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	order, ok := findOrder(c)
+
+	if !ok {
+		return
+	}
+
+	respond(c, http.StatusOK, order)
+}
+
+// PayOrder godoc
+// @Summary      Pay an order
+// @Description  charge the total of a pending order with the payment provider, the stock it held is sold
+// @Tags         orders
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string           true  "ID of the signed in user"
+// @Param        X-User-Token  header    string           true  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string           true  "Order ID"
+// @Param        payment       body      models.PayOrder  true  "Payment"
+// @Success      200  {object}  models.Order
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      402  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Failure      502  {object}  models.ErrorMessage
+// @Router       /v1/orders/{id}/pay [post]
+func PayOrder(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	order, ok := findOrder(c)
+
+	if !ok {
+		return
+	}
+
+	var pay models.PayOrder
+
+	if err := c.ShouldBindWith(&pay, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if validationErr := validate.Struct(pay); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	if !canMoveOrder(c, order, models.OrderPaid) {
+		return
+	}
+
+	now := time.Now()
+
+	if !now.Before(order.Expires_at) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "the order expired"})
+		return
+	}
+
+	paid, err := paymentProvider.Charge(c, payment.Charge{
+		Reference: order.ID.Hex(),
+		Amount:    order.Total,
+		Token:     pay.PaymentToken,
+	})
+
+	if err == payment.ErrDeclined {
+		respond(c, http.StatusPaymentRequired, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("payment failed:", err)
+		respond(c, http.StatusBadGateway, models.ErrorMessage{Error: "the payment failed"})
+		return
+	}
+
+	var updated models.Order
+
+	err = inTransaction(c, func(sc mongo.SessionContext) error {
+		for _, item := range order.Items {
+			if item.ReservationID.IsZero() {
+				continue
+			}
+
+			if _, err := settleReservation(sc, item.ReservationID, models.ReservationCommitted, now); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = setOrderStatus(sc, order, models.OrderPaid, bson.M{"payment_id": paid.ID})
+		return err
+	})
+
+	refunded := false
+
+	if err != nil {
+		// the order can't be sold, give the money back
+		if refundErr := paymentProvider.Refund(c, paid.ID); refundErr != nil {
+			log.Println("refund of payment", paid.ID, "failed:", refundErr)
+		} else {
+			refunded = true
+		}
+	}
+
+	switch err {
+	case nil:
+		respond(c, http.StatusOK, updated)
+	case errReservationExpired, errReservationClosed:
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "the order expired"})
+	case errOrderChanged:
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+	default:
+		log.Println("recording payment", paid.ID, "failed:", err)
+
+		message := "could not record the payment"
+		if refunded {
+			message += ", it was refunded"
+		}
+
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: message})
+	}
+}
+
+// CancelOrder godoc
+// @Summary      Cancel an order
+// @Description  cancel a pending order, the stock it held is released
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string  true  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string  true  "Order ID"
+// @Success      200  {object}  models.Order
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/orders/{id}/cancel [post]
+func CancelOrder(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	order, ok := findOrder(c)
+
+	if !ok || !canMoveOrder(c, order, models.OrderCancelled) {
+		return
+	}
+
+	order, err := cancelOrder(c, order)
+
+	if err == errOrderChanged {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("order cancellation failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not cancel the order"})
+		return
+	}
+
+	respond(c, http.StatusOK, order)
+}
+
+// ShipOrder godoc
+// @Summary      Ship an order
+// @Description  mark a paid order as shipped
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Order ID"
+// @Success      200  {object}  models.Order
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/orders/{id}/ship [post]
+func ShipOrder(c *gin.Context) {
+	moveOrderResponse(c, models.OrderShipped)
+}
+
+// DeliverOrder godoc
+// @Summary      Deliver an order
+// @Description  mark a shipped order as delivered
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Order ID"
+// @Success      200  {object}  models.Order
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/orders/{id}/deliver [post]
+func DeliverOrder(c *gin.Context) {
+	moveOrderResponse(c, models.OrderDelivered)
+}
+
+// RefundOrder godoc
+// @Summary      Refund an order
+// @Description  give the payment of an order back. The stock of an order that was not shipped yet returns on hand.
+// @Tags         orders
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Order ID"
+// @Success      200  {object}  models.Order
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Failure      502  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/orders/{id}/refund [post]
+func RefundOrder(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	order, ok := findOrder(c)
+
+	if !ok || !canMoveOrder(c, order, models.OrderRefunded) {
+		return
+	}
+
+	if err := paymentProvider.Refund(c, order.PaymentID); err != nil && err != payment.ErrAlreadyRefunded {
+		log.Println("refund of payment", order.PaymentID, "failed:", err)
+		respond(c, http.StatusBadGateway, models.ErrorMessage{Error: "the refund failed"})
+		return
+	}
+
+	var updated models.Order
+
+	err := inTransaction(c, func(sc mongo.SessionContext) error {
+		if order.Status == models.OrderPaid {
+			if err := restockOrder(sc, order); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = setOrderStatus(sc, order, models.OrderRefunded, nil)
+		return err
+	})
+
+	if err == errOrderChanged {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("order refund failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "the payment was refunded but the order could not be updated"})
+		return
+	}
+
+	respond(c, http.StatusOK, updated)
+}
+
+// findOrder loads the order of the path for its user, or for an admin token,
+// and responds 404 itself otherwise
+func findOrder(c *gin.Context) (models.Order, bool) {
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var order models.Order
+
+	if err := ordersCollection.FindOne(c, bson.M{"_id": id}).Decode(&order); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "order not found"})
+		return order, false
+	}
+
+	if middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		return order, true
+	}
+
+	user, ok := currentUser(c)
+
+	if !ok {
+		return order, false
+	}
+
+	if user == "" || user != order.UserID {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "order not found"})
+		return order, false
+	}
+
+	return order, true
+}
+
+// canMoveOrder answers 409 when the order can't go to status
+func canMoveOrder(c *gin.Context, order models.Order, status string) bool {
+	for _, next := range orderTransitions[order.Status] {
+		if next == status {
+			return true
+		}
+	}
+
+	respond(c, http.StatusConflict, models.ErrorMessage{Error: "the order is " + order.Status})
+	return false
+}
+
+func moveOrderResponse(c *gin.Context, status string) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	order, ok := findOrder(c)
+
+	if !ok || !canMoveOrder(c, order, status) {
+		return
+	}
+
+	order, err := setOrderStatus(c, order, status, nil)
+
+	if err == errOrderChanged {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("order update failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not update the order"})
+		return
+	}
+
+	respond(c, http.StatusOK, order)
+}
+
+// setOrderStatus moves the order to status along with the fields of set,
+// as long as nobody moved it meanwhile, and returns the moved order
+func setOrderStatus(ctx context.Context, order models.Order, status string, set bson.M) (models.Order, error) {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	event := models.OrderEvent{Status: status, At: now}
+
+	update := bson.M{"status": status, "updated_at": now}
+	for field, value := range set {
+		update[field] = value
+	}
+
+	res, err := ordersCollection.UpdateOne(ctx,
+		bson.M{"_id": order.ID, "status": order.Status},
+		bson.M{"$set": update, "$push": bson.M{"history": event}})
+
+	if err != nil {
+		return order, err
+	}
+
+	if res.ModifiedCount == 0 {
+		return order, errOrderChanged
+	}
+
+	if paymentID, ok := set["payment_id"].(string); ok {
+		order.PaymentID = paymentID
+	}
+
+	order.Status = status
+	order.Updated_at = now
+	order.History = append(append([]models.OrderEvent{}, order.History...), event)
+
+	return order, nil
+}
+
+// cancelOrder cancels a pending order and releases the stock it held
+func cancelOrder(ctx context.Context, order models.Order) (models.Order, error) {
+	var cancelled models.Order
+
+	err := inTransaction(ctx, func(sc mongo.SessionContext) error {
+		for _, item := range order.Items {
+			if item.ReservationID.IsZero() {
+				continue
+			}
+
+			_, err := settleReservation(sc, item.ReservationID, models.ReservationReleased, time.Now())
+
+			// an expired reservation gave its stock back already
+			if err != nil && err != errReservationClosed && err != errReservationNotFound {
+				return err
+			}
+		}
+
+		if err := releasePromotions(sc, order); err != nil {
+			return err
+		}
+
+		var err error
+		cancelled, err = setOrderStatus(sc, order, models.OrderCancelled, nil)
+		return err
+	})
+
+	return cancelled, err
+}
+
+// restockOrder puts the stock sold by the order back on hand
+func restockOrder(sc mongo.SessionContext, order models.Order) error {
+	for _, item := range order.Items {
+		if item.ReservationID.IsZero() {
+			continue
+		}
+
+		inventory, err := changeInventory(sc, bson.M{"_id": item.AlbumID}, item.Quantity, 0, nil)
+
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = recordMovement(sc, models.InventoryMovement{
+			AlbumID:     item.AlbumID,
+			Type:        models.MovementRestock,
+			OnHandDelta: item.Quantity,
+			Reason:      "order " + order.ID.Hex() + " refunded",
+			Inventory:   inventory,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expireOrders cancels the pending orders that weren't paid in time
+func expireOrders(ctx context.Context, now time.Time) error {
+	cursor, err := ordersCollection.Find(ctx,
+		bson.M{"status": models.OrderPending, "expires_at": bson.M{"$lte": now}})
+
+	if err != nil {
+		return err
+	}
+
+	var expired []models.Order
+
+	if err = cursor.All(ctx, &expired); err != nil {
+		return err
+	}
+
+	for _, order := range expired {
+		// paid or cancelled meanwhile
+		if _, err = cancelOrder(ctx, order); err != nil && err != errOrderChanged {
+			return err
+		}
+	}
+
+	return nil
+}
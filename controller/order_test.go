@@ -0,0 +1,151 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderRoutes(t *testing.T) {
+
+	const user = "order-test-user"
+
+	customer := asUser(t, user)
+	stranger := asUser(t, "someone-else")
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Sold album", "artist": "Me Owais", "price": 10, "currency": "USD"}`, admin).Body.Bytes(), &postRes)
+	albumPath := "/albums/" + postRes.InsertedID
+
+	request("POST", albumPath+"/inventory/adjustments", `{"quantity": 3}`, admin)
+	request("DELETE", "/cart", "", customer)
+	request("POST", "/cart/items", `{"album_id": "`+postRes.InsertedID+`", "quantity": 2}`, customer)
+
+	var order models.Order
+	json.Unmarshal(request("POST", "/orders", "", customer).Body.Bytes(), &order)
+
+	assert.Equal(t, models.OrderPending, order.Status)
+	assert.Equal(t, "20.00", order.Total.Amount.String())
+
+	orderPath := "/orders/" + order.ID.Hex()
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:     "the cart is empty after checkout",
+			method:   "POST",
+			path:     "/orders",
+			headers:  customer,
+			response: `{"error":"the cart is empty"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "the stock is held",
+			method:   "GET",
+			path:     albumPath + "/inventory",
+			response: `{"on_hand":3,"reserved":2,"available":1,"low_stock_threshold":0,"low_stock":false}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to read the order of another user",
+			method:   "GET",
+			path:     orderPath,
+			headers:  stranger,
+			response: `{"error":"order not found"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "try to ship an unpaid order",
+			method:   "POST",
+			path:     orderPath + "/ship",
+			headers:  admin,
+			response: `{"error":"the order is pending"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "pay with a declined card",
+			method:   "POST",
+			path:     orderPath + "/pay",
+			body:     `{"payment_token": "tok_declined"}`,
+			headers:  customer,
+			response: `{"error":"payment declined"}`,
+			status:   http.StatusPaymentRequired,
+		},
+		{
+			name:    "pay",
+			method:  "POST",
+			path:    orderPath + "/pay",
+			body:    `{"payment_token": "tok_visa"}`,
+			headers: customer,
+			status:  http.StatusOK,
+		},
+		{
+			name:     "try to cancel a paid order",
+			method:   "POST",
+			path:     orderPath + "/cancel",
+			headers:  customer,
+			response: `{"error":"the order is paid"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "the stock is sold",
+			method:   "GET",
+			path:     albumPath + "/inventory",
+			response: `{"on_hand":1,"reserved":0,"available":1,"low_stock_threshold":0,"low_stock":false}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to refund without the admin token",
+			method:   "POST",
+			path:     orderPath + "/refund",
+			headers:  customer,
+			response: `{"message":"wrong token"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "refund before shipping",
+			method:  "POST",
+			path:    orderPath + "/refund",
+			headers: admin,
+			status:  http.StatusOK,
+		},
+		{
+			name:     "the stock is back",
+			method:   "GET",
+			path:     albumPath + "/inventory",
+			response: `{"on_hand":3,"reserved":0,"available":3,"low_stock_threshold":0,"low_stock":false}`,
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, tc.headers)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var refunded models.Order
+	json.Unmarshal(request("GET", orderPath, "", customer).Body.Bytes(), &refunded)
+
+	assert.Equal(t, models.OrderRefunded, refunded.Status)
+	assert.NotEmpty(t, refunded.PaymentID)
+	assert.Len(t, refunded.History, 3)
+
+	assert.Equal(t, http.StatusOK, request("DELETE", albumPath, "", admin).Code)
+}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pagination reads the 1-based page and the limit query parameters.
+// paginated is false when the client sent neither of them.
+func pagination(c *gin.Context) (page, limit int64, paginated bool, err error) {
+	page, limit = 1, defaultPageSize
+
+	if value, ok := c.GetQuery("page"); ok {
+		paginated = true
+
+		if page, err = strconv.ParseInt(value, 10, 64); err != nil || page < 1 {
+			return 0, 0, true, errors.New("invalid page")
+		}
+	}
+
+	if value, ok := c.GetQuery("limit"); ok {
+		paginated = true
+
+		if limit, err = strconv.ParseInt(value, 10, 64); err != nil || limit < 1 || limit > maxPageSize {
+			return 0, 0, true, errors.New("invalid limit")
+		}
+	}
+
+	return page, limit, paginated, nil
+}
+
+// setTotalCount reports the number of items on all pages
+func setTotalCount(c *gin.Context, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"rest/signing"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// largest preview clip accepted, 30 seconds of high bitrate audio fit easily
+const maxPreviewSize = 5 << 20
+
+// how long a signed preview URL works, PREVIEW_URL_TTL overrides it
+var previewURLTTL = 15 * time.Minute
+
+var previewSigner *signing.Signer
+
+// initPreviews sets up the signing of preview URLs. Without PREVIEW_URL_KEY
+// a random key is used, URLs then stop working when the server restarts.
+func initPreviews() {
+	key := []byte(middlewares.DotEnvVariable("PREVIEW_URL_KEY"))
+
+	if len(key) == 0 {
+		log.Println("PREVIEW_URL_KEY is not set, preview URLs only work until the server restarts")
+
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	previewSigner = signing.New(key)
+
+	if ttl := middlewares.DotEnvVariable("PREVIEW_URL_TTL"); ttl != "" {
+		var err error
+		if previewURLTTL, err = time.ParseDuration(ttl); err != nil {
+			log.Fatal("PREVIEW_URL_TTL: ", err)
+		}
+	}
+}
+
+// PutTrackPreview godoc
+// @Summary      Upload a track preview
+// @Description  upload an MP3, AAC/MP4, Ogg, FLAC or WAV preview clip of at most 5 MB as the "preview" form field, replacing the previous one
+// @Tags         tracks
+// @Accept       mpfd
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id       path      string  true  "Album ID"
+// @Param        track    path      string  true  "Track ID"
+// @Param        preview  formData  file    true  "Preview clip"
+// @Success      200	{object}  models.SuccessMessage
+// @Failure      404	{object}  models.ErrorMessage
+// @Failure      413	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/tracks/{track}/preview [put]
+func PutTrackPreview(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := trackIndex(album.Tracks, c.Param("track"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "track not found"})
+		return
+	}
+
+	trackID := album.Tracks[i].ID
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxPreviewSize+1<<20)
+
+	header, err := c.FormFile("preview")
+
+	if err != nil {
+		if err.Error() == "http: request body too large" {
+			respond(c, http.StatusRequestEntityTooLarge, models.ErrorMessage{Error: "preview is larger than 5 MB"})
+			return
+		}
+
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "missing preview file"})
+		return
+	}
+
+	if header.Size > maxPreviewSize {
+		respond(c, http.StatusRequestEntityTooLarge, models.ErrorMessage{Error: "preview is larger than 5 MB"})
+		return
+	}
+
+	file, err := header.Open()
+
+	if err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "missing preview file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+
+	if err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "could not read the preview"})
+		return
+	}
+
+	contentType := audioContentType(data)
+
+	if contentType == "" {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "preview must be an MP3, AAC/MP4, Ogg, FLAC or WAV file"})
+		return
+	}
+
+	sum := sha256.Sum256(data)
+
+	metadata := bson.M{
+		"album_id":     album.ID,
+		"track_id":     trackID,
+		"content_type": contentType,
+		"etag":         `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+
+	fileID, err := previewsBucket.UploadFromStream(album.ID.Hex()+"/"+trackID.Hex(), bytes.NewReader(data),
+		options.GridFSUpload().SetMetadata(metadata))
+
+	if err != nil {
+		log.Println("preview upload failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not store the preview"})
+		return
+	}
+
+	// the older clips of the track are replaced
+	deletePreviews(c, bson.M{"metadata.track_id": trackID, "_id": bson.M{"$ne": fileID}})
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully uploaded the preview"})
+}
+
+// GetTrackPreviewURL godoc
+// @Summary      Get a preview URL
+// @Description  get a signed URL of the preview clip of a track, it expires after a while so the clip can't be hot-linked
+// @Tags         tracks
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string  true  "Album ID"
+// @Param        track  path      string  true  "Track ID"
+// @Success      200  {object}  models.PreviewURL
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/tracks/{track}/preview/url [get]
+func GetTrackPreviewURL(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := trackIndex(album.Tracks, c.Param("track"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "track not found"})
+		return
+	}
+
+	if _, err := findPreview(c, album.Tracks[i].ID); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "track has no preview"})
+		return
+	}
+
+	path := strings.TrimSuffix(c.Request.URL.Path, "/url")
+	expires := time.Now().Add(previewURLTTL).Truncate(time.Second)
+
+	respond(c, http.StatusOK, models.PreviewURL{
+		URL:        path + "?" + previewSigner.Sign(path, expires).Encode(),
+		Expires_at: expires,
+	})
+}
+
+// GetTrackPreview godoc
+// @Summary      Stream a track preview
+// @Description  stream the preview clip of a track through a signed URL, supports Range requests
+// @Tags         tracks
+// @Produce      audio/mpeg,audio/mp4,audio/ogg,audio/flac,audio/wav
+// @Param        id         path      string  true  "Album ID"
+// @Param        track      path      string  true  "Track ID"
+// @Param        expires    query     int     true  "Expiry of the signed URL"
+// @Param        signature  query     string  true  "Signature of the signed URL"
+// @Param        Range      header    string  false  "Byte range, answered with 206"
+// @Success      200  {file}    file
+// @Success      206  {file}    file
+// @Failure      403  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/tracks/{track}/preview [get]
+func GetTrackPreview(c *gin.Context) {
+	if err := previewSigner.Verify(c.Request.URL.Path, c.Request.URL.Query(), time.Now()); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	trackID, _ := primitive.ObjectIDFromHex(c.Param("track"))
+
+	preview, err := findPreview(c, trackID)
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorMessage{Error: "track has no preview"})
+		return
+	}
+
+	var metadata struct {
+		ContentType string `bson:"content_type"`
+		ETag        string `bson:"etag"`
+	}
+
+	bson.Unmarshal(preview.Metadata, &metadata)
+
+	id, _ := preview.ID.(primitive.ObjectID)
+
+	// the signature in the URL is what grants access, shared caches must not keep the clip
+	c.Header("Cache-Control", "private, no-cache")
+	serveFile(c, previewsBucket, id, preview.Length, metadata.ContentType, metadata.ETag, preview.UploadDate)
+}
+
+// findPreview returns the latest preview clip of a track
+func findPreview(ctx context.Context, trackID primitive.ObjectID) (*gridfs.File, error) {
+	opts := options.GridFSFind().SetSort(bson.M{"uploadDate": -1}).SetLimit(1)
+
+	cursor, err := previewsBucket.Find(bson.M{"metadata.track_id": trackID}, opts)
+
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err = cursor.Err(); err == nil {
+			err = gridfs.ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	var file gridfs.File
+
+	if err = cursor.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// deletePreviews removes the preview files matching filter. Leftover files
+// only waste space, so failures are logged and otherwise ignored.
+func deletePreviews(ctx context.Context, filter bson.M) {
+	cursor, err := previewsBucket.Find(filter)
+
+	if err != nil {
+		log.Println("could not find previews to delete:", err)
+		return
+	}
+
+	var files []gridfs.File
+
+	if err = cursor.All(ctx, &files); err != nil {
+		log.Println("could not find previews to delete:", err)
+		return
+	}
+
+	for _, file := range files {
+		if err := previewsBucket.Delete(file.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			log.Printf("could not delete preview %v: %v", file.ID, err)
+		}
+	}
+}
+
+// deleteOrphanPreviews removes the previews of the tracks an album no longer has
+func deleteOrphanPreviews(ctx context.Context, album models.Album) {
+	ids := make([]primitive.ObjectID, len(album.Tracks))
+	for i, track := range album.Tracks {
+		ids[i] = track.ID
+	}
+
+	deletePreviews(ctx, bson.M{"metadata.album_id": album.ID, "metadata.track_id": bson.M{"$nin": ids}})
+}
+
+// audioContentType recognizes the audio formats accepted as previews by
+// their first bytes, http.DetectContentType misses most of them
+func audioContentType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("ID3")),
+		len(data) > 1 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 && data[1]&0x06 != 0:
+		// an ID3 tag or an MPEG audio frame header with a layer set
+		return "audio/mpeg"
+	case len(data) > 1 && data[0] == 0xFF && data[1]&0xF6 == 0xF0:
+		// ADTS framed AAC, MPEG frame sync with layer 0
+		return "audio/aac"
+	case len(data) > 11 && string(data[4:8]) == "ftyp":
+		return "audio/mp4"
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case len(data) > 11 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return "audio/wav"
+	}
+
+	return ""
+}
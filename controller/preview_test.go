@@ -0,0 +1,130 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackPreviewRoutes(t *testing.T) {
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", apiprefix+"/albums", bytes.NewBufferString(`{
+		"title": "Preview album",
+		"artist": "Me Owais",
+		"price": 10,
+		"tracks": [{"number": 1, "title": "Opening", "duration": 200}]
+	}`))
+	req.Header.Add("Authorization", "owais")
+	router.ServeHTTP(w, req)
+
+	var postRes PostResponse
+	json.Unmarshal(w.Body.Bytes(), &postRes)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", apiprefix+"/albums/"+postRes.InsertedID+"/tracks", nil)
+	router.ServeHTTP(w, req)
+
+	var tracks []models.Track
+	json.Unmarshal(w.Body.Bytes(), &tracks)
+
+	if !assert.Len(t, tracks, 1) {
+		return
+	}
+
+	previewPath := apiprefix + "/albums/" + postRes.InsertedID + "/tracks/" + tracks[0].ID.Hex() + "/preview"
+
+	upload := func(data []byte) int {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("preview", "preview.mp3")
+		part.Write(data)
+		form.Close()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", previewPath, &body)
+		req.Header.Add("Authorization", "owais")
+		req.Header.Add("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, upload([]byte("not audio at all")))
+
+	clip := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x00}, 256)...)
+	assert.Equal(t, http.StatusOK, upload(clip))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", previewPath+"/url", nil)
+	router.ServeHTTP(w, req)
+
+	var signed models.PreviewURL
+	json.Unmarshal(w.Body.Bytes(), &signed)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	test_cases := []struct {
+		name   string
+		url    string
+		header map[string]string
+		status int
+	}{
+		{
+			name:   "stream a preview",
+			url:    signed.URL,
+			status: http.StatusOK,
+		},
+		{
+			name:   "stream part of a preview",
+			url:    signed.URL,
+			header: map[string]string{"Range": "bytes=10-19"},
+			status: http.StatusPartialContent,
+		},
+		{
+			name:   "try to stream a preview without a signature",
+			url:    previewPath,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "try to stream a preview with a forged signature",
+			url:    previewPath + "?expires=4102444800&signature=forged",
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tc.url, nil)
+
+			for key, value := range tc.header {
+				req.Header.Add(key, value)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			switch tc.status {
+			case http.StatusOK:
+				assert.Equal(t, "audio/mpeg", w.Header().Get("Content-Type"))
+				assert.Equal(t, clip, w.Body.Bytes())
+			case http.StatusPartialContent:
+				assert.Equal(t, clip[10:20], w.Body.Bytes())
+			}
+		})
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", apiprefix+"/albums/"+postRes.InsertedID, nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
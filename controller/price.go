@@ -0,0 +1,503 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errScheduleOverlaps = errors.New("the schedule overlaps another scheduled price")
+
+// how often the scheduler looks for scheduled prices to apply or revert
+var priceSchedulerInterval = time.Minute
+
+func initPriceScheduler() {
+	if interval := middlewares.DotEnvVariable("PRICE_SCHEDULER_INTERVAL"); interval != "" {
+		var err error
+		if priceSchedulerInterval, err = time.ParseDuration(interval); err != nil || priceSchedulerInterval <= 0 {
+			log.Fatal("PRICE_SCHEDULER_INTERVAL: invalid duration ", interval)
+		}
+	}
+}
+
+// GetAlbumPriceHistory godoc
+// @Summary      Get the price history of an album
+// @Description  get the price changes of an album, newest first, and its scheduled prices in start order
+// @Tags         prices
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Album ID"
+// @Success      200  {object}  models.PriceHistory
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/price-history [get]
+func GetAlbumPriceHistory(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	history := models.PriceHistory{
+		Currency:  album.Currency,
+		Changes:   []models.PriceChange{},
+		Scheduled: []models.ScheduledPrice{},
+	}
+
+	cursor, err := pricesCollection.Find(c, bson.M{"album_id": album.ID},
+		options.Find().SetSort(bson.D{{Key: "changed_at", Value: -1}, {Key: "_id", Value: -1}}))
+
+	if err == nil {
+		err = cursor.All(c, &history.Changes)
+	}
+
+	if err == nil {
+		cursor, err = schedulesCollection.Find(c, bson.M{"album_id": album.ID},
+			options.Find().SetSort(bson.M{"starts_at": 1}))
+	}
+
+	if err == nil {
+		err = cursor.All(c, &history.Scheduled)
+	}
+
+	if err != nil {
+		log.Println("price history failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the price history"})
+		return
+	}
+
+	respond(c, http.StatusOK, history)
+}
+
+// PostAlbumPriceSchedule godoc
+// @Summary      Schedule a price
+// @Description  schedule a price for an album from starts_at, and until ends_at when given. Scheduled prices can't overlap and albums with variants take their price from the variants.
+// @Tags         prices
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id        path      string                    true  "Album ID"
+// @Param        schedule  body      models.AddScheduledPrice  true  "Scheduled Price"
+// @Success      200	{object}  models.ScheduledPrice
+// @Failure      404	{object}  models.ErrorMessage
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/price-schedules [post]
+func PostAlbumPriceSchedule(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	var add models.AddScheduledPrice
+
+	if err := c.ShouldBindWith(&add, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if validationErr := validate.Struct(add); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	if add.EndsAt != nil && !add.EndsAt.After(now) {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: "ends_at is in the past"})
+		return
+	}
+
+	if len(album.Variants) > 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "the price of an album with variants follows its variants"})
+		return
+	}
+
+	schedule := models.ScheduledPrice{
+		ID:         primitive.NewObjectID(),
+		AlbumID:    album.ID,
+		Price:      models.Amount(add.Price),
+		Starts_at:  add.StartsAt.UTC(),
+		Status:     models.ScheduleStatusPending,
+		Created_at: now,
+	}
+
+	if add.EndsAt != nil {
+		ends := add.EndsAt.UTC()
+		schedule.Ends_at = &ends
+	}
+
+	err := inTransaction(c, func(sc mongo.SessionContext) error {
+		// bumping a counter on the album makes two transactions scheduling
+		// it conflict, the retried one then counts the other schedule
+		if _, err := albumsCollection.UpdateByID(sc, album.ID, bson.M{"$inc": bson.M{"schedules_version": 1}}); err != nil {
+			return err
+		}
+
+		overlaps, err := schedulesCollection.CountDocuments(sc, overlappingSchedules(schedule))
+
+		if err != nil {
+			return err
+		}
+
+		if overlaps > 0 {
+			return errScheduleOverlaps
+		}
+
+		_, err = schedulesCollection.InsertOne(sc, schedule)
+		return err
+	})
+
+	if err == errScheduleOverlaps {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("price schedule failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not schedule the price"})
+		return
+	}
+
+	respond(c, http.StatusOK, schedule)
+}
+
+// DeleteAlbumPriceSchedule godoc
+// @Summary      Cancel a scheduled price
+// @Description  cancel a scheduled price that has not started yet
+// @Tags         prices
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id        path      string  true  "Album ID"
+// @Param        schedule  path      string  true  "Scheduled Price ID"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/price-schedules/{schedule} [delete]
+func DeleteAlbumPriceSchedule(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	albumID, _ := primitive.ObjectIDFromHex(c.Param("id"))
+	id, _ := primitive.ObjectIDFromHex(c.Param("schedule"))
+	filter := bson.M{"_id": id, "album_id": albumID}
+
+	res, err := schedulesCollection.UpdateOne(c,
+		bson.M{"_id": id, "album_id": albumID, "status": models.ScheduleStatusPending},
+		bson.M{"$set": bson.M{"status": models.ScheduleStatusCanceled}})
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not cancel the scheduled price"})
+		return
+	}
+
+	if res.MatchedCount == 0 {
+		if n, _ := schedulesCollection.CountDocuments(c, filter); n > 0 {
+			respond(c, http.StatusConflict, models.ErrorMessage{Error: "only pending scheduled prices can be canceled"})
+			return
+		}
+
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "scheduled price not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully canceled the scheduled price"})
+}
+
+// overlappingSchedules matches the pending and active schedules of the album
+// that clash with s. A schedule without an end is a single point in time, it
+// clashes with the schedules running at that point.
+func overlappingSchedules(s models.ScheduledPrice) bson.M {
+	clashes := bson.A{
+		// s starts while another one runs
+		bson.M{"starts_at": bson.M{"$lte": s.Starts_at}, "ends_at": bson.M{"$gt": s.Starts_at}},
+	}
+
+	if s.Ends_at != nil {
+		// another one starts while s runs
+		clashes = append(clashes, bson.M{"starts_at": bson.M{"$gte": s.Starts_at, "$lt": *s.Ends_at}})
+	} else {
+		clashes = append(clashes, bson.M{"starts_at": s.Starts_at})
+	}
+
+	return bson.M{
+		"album_id": s.AlbumID,
+		"status":   bson.M{"$in": bson.A{models.ScheduleStatusPending, models.ScheduleStatusActive}},
+		"$or":      clashes,
+	}
+}
+
+// recordPriceChange adds an entry to the price history
+func recordPriceChange(ctx context.Context, change models.PriceChange) error {
+	change.ID = primitive.NewObjectID()
+	_, err := pricesCollection.InsertOne(ctx, change)
+	return err
+}
+
+// priceChanged records that the saved album cost previous in currency before
+// and, when its price dropped, tells the users who wish for it. Nothing
+// happens when the price stayed the same.
+func priceChanged(ctx context.Context, album models.Album, previous models.Amount, currency, source string) {
+	if album.Currency == "" {
+		album.Currency = defaultCurrency
+	}
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	if album.Price.Cmp(previous) == 0 && album.Currency == currency {
+		return
+	}
+
+	err := recordPriceChange(ctx, models.PriceChange{
+		AlbumID:    album.ID,
+		Previous:   previous,
+		Price:      album.Price,
+		Currency:   album.Currency,
+		Source:     source,
+		Changed_at: album.Updated_at,
+	})
+
+	if err != nil {
+		log.Println("price history failed:", err)
+	}
+
+	priceDropped(ctx, album, previous, currency)
+}
+
+// priceDropped tells the users who wish for the album when its price dropped
+// from previous in currency
+func priceDropped(ctx context.Context, album models.Album, previous models.Amount, currency string) {
+	if album.Currency != currency || album.Price.Cmp(previous) >= 0 {
+		return
+	}
+
+	if err := notifyPriceDrop(ctx, album, previous); err != nil {
+		log.Println("price drop notifications failed:", err)
+	}
+}
+
+// StartPriceScheduler applies and reverts scheduled prices in the background
+// until ctx is done. Several servers can run it, every schedule is claimed in
+// a transaction.
+func StartPriceScheduler(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(priceSchedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := runPriceSchedules(ctx, time.Now()); err != nil {
+				log.Println("price scheduler:", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runPriceSchedules starts the schedules due at now, then ends the ones over
+func runPriceSchedules(ctx context.Context, now time.Time) error {
+	now, _ = time.Parse(time.RFC3339, now.Format(time.RFC3339))
+
+	opts := options.Find().SetSort(bson.M{"starts_at": 1})
+
+	due, err := findSchedules(ctx, bson.M{"status": models.ScheduleStatusPending, "starts_at": bson.M{"$lte": now}}, opts)
+
+	if err != nil {
+		return err
+	}
+
+	// one schedule failing doesn't hold back the others, it is tried again
+	// on the next run
+	for _, s := range due {
+		if err = startSchedule(ctx, s, now); err != nil {
+			log.Println("starting price schedule", s.ID.Hex(), "failed:", err)
+		}
+	}
+
+	over, err := findSchedules(ctx, bson.M{"status": models.ScheduleStatusActive, "ends_at": bson.M{"$lte": now}}, opts)
+
+	if err != nil {
+		return err
+	}
+
+	for _, s := range over {
+		if err = endSchedule(ctx, s, now); err != nil {
+			log.Println("ending price schedule", s.ID.Hex(), "failed:", err)
+		}
+	}
+
+	return nil
+}
+
+func findSchedules(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.ScheduledPrice, error) {
+	cursor, err := schedulesCollection.Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var schedules []models.ScheduledPrice
+
+	err = cursor.All(ctx, &schedules)
+	return schedules, err
+}
+
+// startSchedule gives the album its scheduled price, keeping the current one
+// to restore at the end
+func startSchedule(ctx context.Context, s models.ScheduledPrice, now time.Time) error {
+	var album models.Album
+	applied := false
+
+	err := inTransaction(ctx, func(sc mongo.SessionContext) error {
+		album, applied = models.Album{}, false
+
+		err := albumsCollection.FindOne(sc, bson.M{"_id": s.AlbumID}).Decode(&album)
+
+		if err == mongo.ErrNoDocuments {
+			_, err = schedulesCollection.UpdateOne(sc,
+				bson.M{"_id": s.ID, "status": models.ScheduleStatusPending},
+				bson.M{"$set": bson.M{"status": models.ScheduleStatusCanceled}})
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		set := bson.M{"status": models.ScheduleStatusActive, "previous": album.Price, "applied_at": now}
+
+		if s.Ends_at == nil {
+			set["status"] = models.ScheduleStatusCompleted
+			set["ended_at"] = now
+		}
+
+		res, err := schedulesCollection.UpdateOne(sc, bson.M{"_id": s.ID, "status": models.ScheduleStatusPending}, bson.M{"$set": set})
+
+		if err != nil || res.ModifiedCount == 0 {
+			// another server got it first
+			return err
+		}
+
+		if _, err = albumsCollection.UpdateByID(sc, album.ID, bson.M{"$set": bson.M{"price": s.Price, "updated_at": now}}); err != nil {
+			return err
+		}
+
+		err = recordPriceChange(sc, models.PriceChange{
+			AlbumID:    album.ID,
+			Previous:   album.Price,
+			Price:      s.Price,
+			Currency:   album.Currency,
+			Source:     models.PriceSourceScheduleStart,
+			ScheduleID: s.ID,
+			Changed_at: now,
+		})
+		applied = err == nil
+		return err
+	})
+
+	if err == nil && applied {
+		previous := album.Price
+		album.Price = s.Price
+		priceDropped(ctx, album, previous, album.Currency)
+	}
+
+	return err
+}
+
+// endSchedule restores the price the album had before the schedule, unless
+// the price was changed by hand in the meantime
+func endSchedule(ctx context.Context, s models.ScheduledPrice, now time.Time) error {
+	var album models.Album
+	restored := false
+
+	err := inTransaction(ctx, func(sc mongo.SessionContext) error {
+		album, restored = models.Album{}, false
+
+		res, err := schedulesCollection.UpdateOne(sc,
+			bson.M{"_id": s.ID, "status": models.ScheduleStatusActive},
+			bson.M{"$set": bson.M{"status": models.ScheduleStatusCompleted, "ended_at": now}})
+
+		if err != nil || res.ModifiedCount == 0 {
+			return err
+		}
+
+		err = albumsCollection.FindOne(sc, bson.M{"_id": s.AlbumID}).Decode(&album)
+
+		if err == mongo.ErrNoDocuments || (err == nil && album.Price.Cmp(s.Price) != 0) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err = albumsCollection.UpdateByID(sc, album.ID, bson.M{"$set": bson.M{"price": s.Previous, "updated_at": now}}); err != nil {
+			return err
+		}
+
+		err = recordPriceChange(sc, models.PriceChange{
+			AlbumID:    album.ID,
+			Previous:   album.Price,
+			Price:      s.Previous,
+			Currency:   album.Currency,
+			Source:     models.PriceSourceScheduleEnd,
+			ScheduleID: s.ID,
+			Changed_at: now,
+		})
+		restored = err == nil
+		return err
+	})
+
+	if err == nil && restored {
+		previous := album.Price
+		album.Price = s.Previous
+		priceDropped(ctx, album, previous, album.Currency)
+	}
+
+	return err
+}
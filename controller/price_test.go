@@ -0,0 +1,106 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceScheduleRoutes(t *testing.T) {
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Sale album", "artist": "Me Owais", "price": 20}`, admin).Body.Bytes(), &postRes)
+
+	albumPath := "/albums/" + postRes.InsertedID
+	start := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	end := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+
+	var sale models.ScheduledPrice
+	json.Unmarshal(request("POST", albumPath+"/price-schedules", `{"price": 15, "starts_at": "`+start+`", "ends_at": "`+end+`"}`, admin).Body.Bytes(), &sale)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		response string
+		status   int
+	}{
+		{
+			name:     "change the price",
+			method:   "PATCH",
+			path:     albumPath,
+			body:     `{"price": 18}`,
+			response: `{"message":"successfully updated the album"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to schedule an overlapping price",
+			method:   "POST",
+			path:     albumPath + "/price-schedules",
+			body:     `{"price": 12, "starts_at": "` + start + `"}`,
+			response: `{"error":"the schedule overlaps another scheduled price"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:   "try to schedule a price ending before it starts",
+			method: "POST",
+			path:   albumPath + "/price-schedules",
+			body:   `{"price": 12, "starts_at": "` + end + `", "ends_at": "` + start + `"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "cancel a scheduled price",
+			method:   "DELETE",
+			path:     albumPath + "/price-schedules/" + sale.ID.Hex(),
+			response: `{"message":"successfully canceled the scheduled price"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to cancel a canceled price",
+			method:   "DELETE",
+			path:     albumPath + "/price-schedules/" + sale.ID.Hex(),
+			response: `{"error":"only pending scheduled prices can be canceled"}`,
+			status:   http.StatusConflict,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, admin)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	// batch updates are in the history too
+	w := request("PATCH", "/albums:batchUpdate", `{"albums": [{"_id": "`+postRes.InsertedID+`", "price": 16}]}`, admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var history models.PriceHistory
+	json.Unmarshal(request("GET", albumPath+"/price-history", "", admin).Body.Bytes(), &history)
+
+	if assert.Len(t, history.Changes, 2) {
+		assert.Equal(t, models.Amount(18), history.Changes[0].Previous)
+		assert.Equal(t, models.Amount(16), history.Changes[0].Price)
+		assert.Equal(t, models.PriceSourceBatch, history.Changes[0].Source)
+
+		assert.Equal(t, models.Amount(20), history.Changes[1].Previous)
+		assert.Equal(t, models.Amount(18), history.Changes[1].Price)
+		assert.Equal(t, models.PriceSourceUpdate, history.Changes[1].Source)
+	}
+
+	if assert.Len(t, history.Scheduled, 1) {
+		assert.Equal(t, models.ScheduleStatusCanceled, history.Scheduled[0].Status)
+	}
+
+	assert.Equal(t, http.StatusOK, request("DELETE", albumPath, "", admin).Code)
+}
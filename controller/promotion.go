@@ -0,0 +1,720 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"rest/pricing"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errPromotionUsedUp = errors.New("a promotion was used up meanwhile, quote again")
+
+// quoteItem is a quantity of an album to price
+type quoteItem struct {
+	albumID  primitive.ObjectID
+	quantity int
+}
+
+// GetPromotions godoc
+// @Summary      Get all promotions
+// @Description  get the promotions, newest first
+// @Tags         promotions
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        page   query     int     false  "Page number, starting at 1"
+// @Param        limit  query     int     false  "Promotions per page, at most 100"
+// @Success      200  {array}   models.Promotion
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/promotions [get]
+func GetPromotions(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	opts := options.Find().SetSort(bson.M{"_id": -1})
+
+	if paginated {
+		total, err := promotionsCollection.CountDocuments(c, bson.M{})
+
+		if err != nil {
+			log.Println("promotions failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the promotions"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	cursor, err := promotionsCollection.Find(c, bson.M{}, opts)
+
+	if err != nil {
+		log.Println("promotions failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the promotions"})
+		return
+	}
+
+	promotions := []models.Promotion{}
+
+	if err = cursor.All(c, &promotions); err != nil {
+		log.Println("promotions failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the promotions"})
+		return
+	}
+
+	respond(c, http.StatusOK, promotions)
+}
+
+// GetPromotionByID godoc
+// @Summary      Get a promotion
+// @Description  get promotion by ID
+// @Tags         promotions
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Promotion ID"
+// @Success      200  {object}  models.Promotion
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/promotions/{id} [get]
+func GetPromotionByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var promotion models.Promotion
+
+	if err := promotionsCollection.FindOne(c, bson.M{"_id": id}).Decode(&promotion); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "promotion not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, promotion)
+}
+
+// PostPromotion godoc
+// @Summary      Add a promotion
+// @Description  add a percent, fixed or buy some get some free promotion, codes are unique regardless of case
+// @Tags         promotions
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        promotion  body      models.AddPromotion  true  "Add Promotion"
+// @Success      200  {object}  models.Promotion
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/promotions [post]
+func PostPromotion(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var promotion models.Promotion
+
+	if err := c.ShouldBindWith(&promotion, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	preparePromotion(&promotion)
+
+	if validationErr := validate.Struct(promotion); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	promotion.ID = primitive.NewObjectID()
+	promotion.Uses = 0
+	promotion.Created_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	promotion.Updated_at = promotion.Created_at
+
+	_, err := promotionsCollection.InsertOne(c, promotion)
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "code already exists"})
+		return
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "Promotion was not created"})
+		return
+	}
+
+	respond(c, http.StatusOK, promotion)
+}
+
+// UpdatePromotion godoc
+// @Summary      Update a promotion
+// @Description  update promotion by json
+// @Tags         promotions
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id         path      string               true  "Promotion ID"
+// @Param        promotion  body      models.AddPromotion  true  "Update Promotion"
+// @Success      200  {object}  models.SuccessMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/promotions/{id} [patch]
+func UpdatePromotion(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var promotion models.Promotion
+
+	if err := promotionsCollection.FindOne(c, bson.M{"_id": id}).Decode(&promotion); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "promotion not found"})
+		return
+	}
+
+	if err := c.ShouldBindWith(&promotion, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	promotion.ID = id
+	preparePromotion(&promotion)
+
+	if validationErr := validate.Struct(promotion); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	promotion.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	// the uses are counted by the orders only, leave them out of the update
+	promotion.Uses = 0
+	update := bson.M{"$set": promotion}
+
+	unset := bson.M{}
+	if promotion.Code == "" {
+		unset["code"] = ""
+	}
+	if promotion.Starts_at == nil {
+		unset["starts_at"] = ""
+	}
+	if promotion.Ends_at == nil {
+		unset["ends_at"] = ""
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	res, err := promotionsCollection.UpdateByID(c, id, update)
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "code already exists"})
+		return
+	}
+
+	if err != nil || res.MatchedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "promotion not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the promotion"})
+}
+
+// DeletePromotionByID godoc
+// @Summary      Delete a promotion
+// @Description  delete promotion by ID, the orders keep the discounts they got
+// @Tags         promotions
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Promotion ID"
+// @Success      200  {object}  models.SuccessMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/promotions/{id} [delete]
+func DeletePromotionByID(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	res, _ := promotionsCollection.DeleteOne(c, bson.M{"_id": id})
+
+	if res.DeletedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "promotion not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the promotion"})
+}
+
+// PostQuote godoc
+// @Summary      Quote albums
+// @Description  compute what albums cost with the promotions running and the codes given. Among the promotions that apply, the stackable ones all combine, the others apply alone: the quote takes whatever gives the largest discount. With a destination, shipping and tax are added.
+// @Tags         promotions
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string               false  "ID of the signed in user, for the codes limited per user"
+// @Param        X-User-Token  header    string               false  "Token signed for the user, see /users/{id}/token"
+// @Param        quote         body      models.QuoteRequest  true   "Albums to quote"
+// @Success      200  {object}  models.Quote
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/pricing/quote [post]
+func PostQuote(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	user, ok := currentUser(c)
+
+	if !ok {
+		return
+	}
+
+	var req models.QuoteRequest
+
+	if err := c.ShouldBindWith(&req, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+
+	if req.Destination != nil {
+		prepareDestination(req.Destination)
+	}
+
+	if validationErr := validate.Struct(req); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	items := make([]quoteItem, 0, len(req.Items))
+	ids := make([]primitive.ObjectID, 0, len(req.Items))
+
+	for _, item := range req.Items {
+		id, err := primitive.ObjectIDFromHex(item.AlbumID)
+
+		if err != nil {
+			respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found: " + item.AlbumID})
+			return
+		}
+
+		items = append(items, quoteItem{albumID: id, quantity: item.Quantity})
+		ids = append(ids, id)
+	}
+
+	albums, err := findAlbums(c, ids)
+
+	if err != nil {
+		log.Println("quote failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the albums"})
+		return
+	}
+
+	for _, id := range ids {
+		if _, found := albums[id]; !found {
+			respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found: " + id.Hex()})
+			return
+		}
+	}
+
+	quote, _, err := quotePrices(c, albums, items, req.Currency, req.Codes, user)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err = addTaxAndShipping(&quote, albums, req.Destination); err != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	respond(c, http.StatusOK, quote)
+}
+
+func preparePromotion(promotion *models.Promotion) {
+	promotion.Name = strings.TrimSpace(promotion.Name)
+	promotion.Code = strings.ToUpper(strings.TrimSpace(promotion.Code))
+	promotion.Currency = strings.ToUpper(strings.TrimSpace(promotion.Currency))
+
+	if promotion.Type == models.PromotionFixed && promotion.Currency == "" {
+		promotion.Currency = defaultCurrency
+	}
+}
+
+// quotePrices prices the items in currency, with the promotions running and
+// those of the codes. It returns the promotions the quote applies. The albums
+// of every item must be in albums.
+func quotePrices(ctx context.Context, albums map[primitive.ObjectID]models.Album, items []quoteItem, currency string, codes []string, user string) (models.Quote, []models.Promotion, error) {
+	if currency == "" {
+		currency = cartCurrency(albums)
+	}
+
+	places := currencyPlaces(currency)
+	lines := make([]pricing.Line, 0, len(items))
+
+	for _, item := range items {
+		album := albums[item.albumID]
+
+		price, err := convertPrice(album.Price, album.Currency, currency)
+
+		if err != nil {
+			return models.Quote{}, nil, noExchangeRate(album.Currency, currency)
+		}
+
+		lines = append(lines, pricing.Line{
+			AlbumID:  album.ID,
+			ArtistID: album.ArtistID,
+			Genres:   album.Genres,
+			Quantity: item.quantity,
+			Price:    price.Amount.Rat(),
+		})
+	}
+
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		normalized = append(normalized, strings.ToUpper(strings.TrimSpace(code)))
+	}
+
+	cursor, err := promotionsCollection.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"code": bson.M{"$exists": false}},
+		bson.M{"code": bson.M{"$in": normalized}},
+	}})
+
+	if err != nil {
+		return models.Quote{}, nil, err
+	}
+
+	var promotions []models.Promotion
+
+	if err = cursor.All(ctx, &promotions); err != nil {
+		return models.Quote{}, nil, err
+	}
+
+	quote := models.Quote{Items: []models.QuoteLine{}, Promotions: []models.AppliedPromotion{}}
+	known := map[string]bool{}
+	rules := []pricing.Rule{}
+	byID := map[primitive.ObjectID]models.Promotion{}
+
+	for _, promotion := range promotions {
+		known[promotion.Code] = true
+
+		rule, reason, err := promotionRule(ctx, promotion, currency, user)
+
+		if err != nil {
+			return models.Quote{}, nil, err
+		}
+
+		if reason != "" {
+			if promotion.Code != "" {
+				quote.Rejected = append(quote.Rejected, models.RejectedCode{Code: promotion.Code, Reason: reason})
+			}
+			continue
+		}
+
+		rules = append(rules, rule)
+		byID[promotion.ID] = promotion
+	}
+
+	for _, code := range normalized {
+		if !known[code] {
+			quote.Rejected = append(quote.Rejected, models.RejectedCode{Code: code, Reason: "unknown code"})
+		}
+	}
+
+	result := pricing.Apply(lines, rules, places)
+
+	subtotal, discount := new(big.Rat), new(big.Rat)
+
+	for i, line := range lines {
+		lineSubtotal := new(big.Rat).Mul(line.Price, big.NewRat(int64(line.Quantity), 1))
+		subtotal.Add(subtotal, lineSubtotal)
+		discount.Add(discount, result.Lines[i])
+
+		quote.Items = append(quote.Items, models.QuoteLine{
+			AlbumID:  line.AlbumID,
+			Quantity: line.Quantity,
+			Price:    money(line.Price, currency),
+			Subtotal: money(lineSubtotal, currency),
+			Discount: money(result.Lines[i], currency),
+			Total:    money(new(big.Rat).Sub(lineSubtotal, result.Lines[i]), currency),
+		})
+	}
+
+	quote.Subtotal = money(subtotal, currency)
+	quote.Discount = money(discount, currency)
+	quote.Total = money(new(big.Rat).Sub(subtotal, discount), currency)
+
+	var applied []models.Promotion
+
+	for _, a := range result.Applied {
+		promotion := byID[a.ID]
+		applied = append(applied, promotion)
+
+		quote.Promotions = append(quote.Promotions, models.AppliedPromotion{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			Code:        promotion.Code,
+			Discount:    money(a.Discount, currency),
+		})
+	}
+
+	for _, rule := range rules {
+		promotion := byID[rule.ID]
+
+		if promotion.Code == "" || containsPromotion(applied, promotion.ID) {
+			continue
+		}
+
+		reason := "doesn't apply to these albums"
+		if pricing.Applies(lines, rule, places) {
+			reason = "doesn't combine with the other promotions"
+		}
+
+		quote.Rejected = append(quote.Rejected, models.RejectedCode{Code: promotion.Code, Reason: reason})
+	}
+
+	return quote, applied, nil
+}
+
+// promotionRule turns the promotion into a pricing rule in currency, or
+// tells why the promotion can't be used now
+func promotionRule(ctx context.Context, promotion models.Promotion, currency string, user string) (pricing.Rule, string, error) {
+	now := time.Now()
+
+	if promotion.Starts_at != nil && now.Before(*promotion.Starts_at) {
+		return pricing.Rule{}, "not valid yet", nil
+	}
+
+	if promotion.Ends_at != nil && !now.Before(*promotion.Ends_at) {
+		return pricing.Rule{}, "expired", nil
+	}
+
+	if promotion.UsageLimit > 0 && promotion.Uses >= promotion.UsageLimit {
+		return pricing.Rule{}, "used up", nil
+	}
+
+	if promotion.PerUserLimit > 0 {
+		if user == "" {
+			return pricing.Rule{}, "sign in to use it", nil
+		}
+
+		uses, err := redemptionsCollection.CountDocuments(ctx, bson.M{"promotion_id": promotion.ID, "user_id": user})
+
+		if err != nil {
+			return pricing.Rule{}, "", err
+		}
+
+		if uses >= int64(promotion.PerUserLimit) {
+			return pricing.Rule{}, "already used", nil
+		}
+	}
+
+	rule := pricing.Rule{
+		ID:        promotion.ID,
+		Type:      promotion.Type,
+		Value:     promotion.Value.Decimal().Rat(),
+		Buy:       promotion.Buy,
+		Get:       promotion.Get,
+		Artists:   promotion.Scope.Artists,
+		Albums:    promotion.Scope.Albums,
+		Stackable: promotion.Stackable,
+	}
+
+	if promotion.Type == models.PromotionFixed {
+		value, err := convertPrice(promotion.Value, promotion.Currency, currency)
+
+		if err != nil {
+			return pricing.Rule{}, "not available in " + currency, nil
+		}
+
+		rule.Value = value.Amount.Rat()
+	}
+
+	for _, genre := range promotion.Scope.Genres {
+		subtree, err := genreSubtree(ctx, genre)
+
+		if err == errGenreNotFound {
+			continue
+		}
+
+		if err != nil {
+			return pricing.Rule{}, "", err
+		}
+
+		rule.Genres = append(rule.Genres, subtree...)
+	}
+
+	return rule, "", nil
+}
+
+// redeemPromotions counts the use of its promotions by the order, within
+// the transaction of sc
+func redeemPromotions(sc mongo.SessionContext, order models.Order, promotions []models.Promotion) error {
+	for _, promotion := range promotions {
+		filter := bson.M{"_id": promotion.ID}
+		if promotion.UsageLimit > 0 {
+			// a promotion nobody used has no uses yet
+			filter["uses"] = bson.M{"$not": bson.M{"$gte": promotion.UsageLimit}}
+		}
+
+		res, err := promotionsCollection.UpdateOne(sc, filter, bson.M{"$inc": bson.M{"uses": 1}})
+
+		if err != nil {
+			return err
+		}
+
+		if res.MatchedCount == 0 {
+			return errPromotionUsedUp
+		}
+
+		if promotion.PerUserLimit > 0 {
+			uses, err := redemptionsCollection.CountDocuments(sc, bson.M{"promotion_id": promotion.ID, "user_id": order.UserID})
+
+			if err != nil {
+				return err
+			}
+
+			if uses >= int64(promotion.PerUserLimit) {
+				return errPromotionUsedUp
+			}
+		}
+
+		_, err = redemptionsCollection.InsertOne(sc, models.Redemption{
+			ID:          primitive.NewObjectID(),
+			PromotionID: promotion.ID,
+			UserID:      order.UserID,
+			OrderID:     order.ID,
+			Created_at:  order.Created_at,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releasePromotions gives back the uses of the promotions of a cancelled
+// order, within the transaction of sc
+func releasePromotions(sc mongo.SessionContext, order models.Order) error {
+	for _, applied := range order.Promotions {
+		res, err := redemptionsCollection.DeleteOne(sc, bson.M{"promotion_id": applied.PromotionID, "order_id": order.ID})
+
+		if err != nil {
+			return err
+		}
+
+		if res.DeletedCount == 0 {
+			continue
+		}
+
+		_, err = promotionsCollection.UpdateOne(sc,
+			bson.M{"_id": applied.PromotionID, "uses": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"uses": -1}})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsPromotion(promotions []models.Promotion, id primitive.ObjectID) bool {
+	for _, promotion := range promotions {
+		if promotion.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// money rounds r to the minor unit of currency
+func money(r *big.Rat, currency string) models.Money {
+	amount, _ := models.DecimalFromRat(r, currencyPlaces(currency))
+	return models.Money{Amount: amount, Currency: currency}
+}
@@ -0,0 +1,101 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionRoutes(t *testing.T) {
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Promoted album", "artist": "Me Owais", "price": 10, "currency": "USD"}`, admin).Body.Bytes(), &postRes)
+	albumID := postRes.InsertedID
+
+	var promotion models.Promotion
+	json.Unmarshal(request("POST", "/promotions", `{"name": "Launch", "code": "launch20", "type": "percent", "value": 20, "scope": {"albums": ["`+albumID+`"]}, "per_user_limit": 1}`, admin).Body.Bytes(), &promotion)
+
+	assert.Equal(t, "LAUNCH20", promotion.Code)
+
+	quoteBody := func(codes string) string {
+		return `{"items": [{"album_id": "` + albumID + `", "quantity": 2}], "codes": [` + codes + `]}`
+	}
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:     "try to reuse a code",
+			method:   "POST",
+			path:     "/promotions",
+			body:     `{"name": "Copy", "code": "Launch20", "type": "fixed", "value": 1}`,
+			response: `{"error":"code already exists"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:   "try to take more than everything off",
+			method: "POST",
+			path:   "/promotions",
+			body:   `{"name": "Too much", "type": "percent", "value": 150}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "try to give albums away for nothing bought",
+			method: "POST",
+			path:   "/promotions",
+			body:   `{"name": "Free", "type": "bogo", "get": 1}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to quote a missing album",
+			method:   "POST",
+			path:     "/pricing/quote",
+			body:     `{"items": [{"album_id": "000000000000000000000000", "quantity": 1}]}`,
+			response: `{"error":"album not found: 000000000000000000000000"}`,
+			status:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, withHeaders(admin, tc.headers))
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var quote models.Quote
+	json.Unmarshal(request("POST", "/pricing/quote", quoteBody(`"launch20"`), withHeaders(admin, asUser(t, "promotion-test-user"))).Body.Bytes(), &quote)
+
+	assert.Equal(t, "20.00", quote.Subtotal.Amount.String())
+	assert.Equal(t, "4.00", quote.Discount.Amount.String())
+	assert.Equal(t, "16.00", quote.Total.Amount.String())
+	if assert.Len(t, quote.Promotions, 1) {
+		assert.Equal(t, promotion.ID, quote.Promotions[0].PromotionID)
+	}
+
+	var anonymous models.Quote
+	json.Unmarshal(request("POST", "/pricing/quote", quoteBody(`"LAUNCH20", "NOPE"`), admin).Body.Bytes(), &anonymous)
+
+	assert.Equal(t, "0.00", anonymous.Discount.Amount.String())
+	assert.Equal(t, []models.RejectedCode{
+		{Code: "LAUNCH20", Reason: "sign in to use it"},
+		{Code: "NOPE", Reason: "unknown code"},
+	}, anonymous.Rejected)
+
+	assert.Equal(t, http.StatusOK, request("DELETE", "/promotions/"+promotion.ID.Hex(), "", admin).Code)
+	assert.Equal(t, http.StatusOK, request("DELETE", "/albums/"+albumID, "", admin).Code)
+}
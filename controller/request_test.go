@@ -0,0 +1,57 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// admin are the headers of a request made with the admin token
+var admin = map[string]string{"Authorization": "owais"}
+
+// asUser are the headers of a request signed in as user, with a token the
+// API signed for them
+func asUser(t *testing.T, user string) map[string]string {
+	t.Helper()
+
+	w := request("POST", "/users/"+user+"/token", "", admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var token models.UserToken
+	json.Unmarshal(w.Body.Bytes(), &token)
+
+	return map[string]string{"X-User-ID": user, "X-User-Token": token.Token}
+}
+
+// request sends a request for path under apiprefix to the router, with the
+// headers given, which may be nil
+func request(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	return serve(method, apiprefix+path, body, headers)
+}
+
+// serve sends a request for url to the router
+func serve(method, url, body string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	for name, value := range headers {
+		req.Header.Add(name, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// withHeaders merges sets of headers, the later ones win
+func withHeaders(sets ...map[string]string) map[string]string {
+	headers := map[string]string{}
+	for _, set := range sets {
+		for name, value := range set {
+			headers[name] = value
+		}
+	}
+	return headers
+}
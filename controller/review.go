@@ -0,0 +1,559 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errReviewNotFound = errors.New("review not found")
+
+// GetAlbumReviews godoc
+// @Summary      Get the reviews of an album
+// @Description  get the reviews of an album, newest first. Flagged reviews are hidden, an admin token can list the reviews of any status with ?status.
+// @Tags         reviews
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id      path      string  true   "Album ID"
+// @Param        status  query     string  false  "One of published, approved or flagged, needs the admin token"
+// @Param        page    query     int     false  "Page number, starting at 1"
+// @Param        limit   query     int     false  "Reviews per page, at most 100"
+// @Success      200  {array}   models.Review
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/reviews [get]
+func GetAlbumReviews(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	page, limit, paginated, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"album_id": album.ID, "status": bson.M{"$ne": models.ReviewFlagged}}
+
+	if status := c.Query("status"); status != "" {
+		if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+			respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+			return
+		}
+
+		if err := validate.Var(status, "oneof=published approved flagged"); err != nil {
+			respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid status"})
+			return
+		}
+
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	if paginated {
+		total, err := reviewsCollection.CountDocuments(c, filter)
+
+		if err != nil {
+			log.Println("reviews failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the reviews"})
+			return
+		}
+
+		setTotalCount(c, total)
+		opts.SetSkip((page - 1) * limit).SetLimit(limit)
+	}
+
+	reviews := []models.Review{}
+
+	cursor, err := reviewsCollection.Find(c, filter, opts)
+
+	if err == nil {
+		err = cursor.All(c, &reviews)
+	}
+
+	if err != nil {
+		log.Println("reviews failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the reviews"})
+		return
+	}
+
+	respond(c, http.StatusOK, reviews)
+}
+
+// GetAlbumReview godoc
+// @Summary      Get a review
+// @Description  get a review of an album by ID. A flagged review is only shown to its author and with the admin token.
+// @Tags         reviews
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  false  "ID of the signed in user"
+// @Param        X-User-Token  header    string  false  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string  true   "Album ID"
+// @Param        review        path      string  true   "Review ID"
+// @Success      200  {object}  models.Review
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/reviews/{review} [get]
+func GetAlbumReview(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := currentUser(c)
+
+	if !ok {
+		return
+	}
+
+	review, err := findReview(c, c.Param("id"), c.Param("review"))
+
+	if err == nil && review.Status == models.ReviewFlagged &&
+		(user == "" || review.UserID != user) && !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		err = errReviewNotFound
+	}
+
+	if err == errReviewNotFound {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("review failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the review"})
+		return
+	}
+
+	respond(c, http.StatusOK, review)
+}
+
+// PostAlbumReview godoc
+// @Summary      Review an album
+// @Description  rate an album from 1 to 5 and review it, a user reviews an album once and edits the review afterwards
+// @Tags         reviews
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string            true  "ID of the signed in user"
+// @Param        X-User-Token  header    string            true  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string            true  "Album ID"
+// @Param        review        body      models.AddReview  true  "Review"
+// @Success      200  {object}  models.Review
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      409  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/reviews [post]
+func PostAlbumReview(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	var add models.AddReview
+
+	if err := c.ShouldBindWith(&add, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	add.Title = strings.TrimSpace(add.Title)
+	add.Body = strings.TrimSpace(add.Body)
+
+	if validationErr := validate.Struct(add); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	review := models.Review{
+		ID:         primitive.NewObjectID(),
+		AlbumID:    album.ID,
+		UserID:     user,
+		Rating:     add.Rating,
+		Title:      add.Title,
+		Body:       add.Body,
+		Status:     models.ReviewPublished,
+		Created_at: now,
+		Updated_at: now,
+	}
+
+	err := inTransaction(c, func(sc mongo.SessionContext) error {
+		if _, err := reviewsCollection.InsertOne(sc, review); err != nil {
+			return err
+		}
+
+		return updateAlbumRating(sc, album.ID, now)
+	})
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "you already reviewed this album"})
+		return
+	}
+
+	if err != nil {
+		log.Println("review failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not save the review"})
+		return
+	}
+
+	respond(c, http.StatusOK, review)
+}
+
+// UpdateAlbumReview godoc
+// @Summary      Edit a review
+// @Description  change the rating, title or body of a review of the user. An approved review waits for moderation again, a flagged one stays flagged.
+// @Tags         reviews
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string               true  "ID of the signed in user"
+// @Param        X-User-Token  header    string               true  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string               true  "Album ID"
+// @Param        review        path      string               true  "Review ID"
+// @Param        changes       body      models.UpdateReview  true  "Changes"
+// @Success      200  {object}  models.Review
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/reviews/{review} [patch]
+func UpdateAlbumReview(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	review, err := findReview(c, c.Param("id"), c.Param("review"))
+
+	if err == errReviewNotFound || (err == nil && review.UserID != user) {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: errReviewNotFound.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("review failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the review"})
+		return
+	}
+
+	var changes models.UpdateReview
+
+	if err := c.ShouldBindWith(&changes, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if validationErr := validate.Struct(changes); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	if changes.Rating != nil {
+		review.Rating = *changes.Rating
+	}
+
+	if changes.Title != nil {
+		review.Title = strings.TrimSpace(*changes.Title)
+	}
+
+	if changes.Body != nil {
+		review.Body = strings.TrimSpace(*changes.Body)
+	}
+
+	if review.Status == models.ReviewApproved {
+		review.Status = models.ReviewPublished
+	}
+
+	review.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	err = inTransaction(c, func(sc mongo.SessionContext) error {
+		return saveReview(sc, review, bson.M{
+			"rating":     review.Rating,
+			"title":      review.Title,
+			"body":       review.Body,
+			"status":     review.Status,
+			"updated_at": review.Updated_at,
+		})
+	})
+
+	if err == errReviewNotFound {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("review failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not save the review"})
+		return
+	}
+
+	respond(c, http.StatusOK, review)
+}
+
+// DeleteAlbumReview godoc
+// @Summary      Delete a review
+// @Description  delete a review of the user, or any review with the admin token
+// @Tags         reviews
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  false  "ID of the signed in user"
+// @Param        X-User-Token  header    string  false  "Token signed for the user, see /users/{id}/token"
+// @Param        id            path      string  true   "Album ID"
+// @Param        review        path      string  true   "Review ID"
+// @Success      200  {object}  models.SuccessMessage
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/reviews/{review} [delete]
+func DeleteAlbumReview(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := currentUser(c)
+
+	if !ok {
+		return
+	}
+
+	review, err := findReview(c, c.Param("id"), c.Param("review"))
+
+	if err == nil && (user == "" || review.UserID != user) && !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		err = errReviewNotFound
+	}
+
+	if err == nil {
+		err = inTransaction(c, func(sc mongo.SessionContext) error {
+			res, err := reviewsCollection.DeleteOne(sc, bson.M{"_id": review.ID})
+
+			if err != nil {
+				return err
+			}
+
+			if res.DeletedCount == 0 {
+				return errReviewNotFound
+			}
+
+			now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+			return updateAlbumRating(sc, review.AlbumID, now)
+		})
+	}
+
+	if err == errReviewNotFound {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("review failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not delete the review"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the review"})
+}
+
+// ModerateAlbumReview godoc
+// @Summary      Moderate a review
+// @Description  approve or flag a review, or publish it again. Flagged reviews are hidden and don't count in the rating of the album.
+// @Tags         reviews
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id      path      string                 true  "Album ID"
+// @Param        review  path      string                 true  "Review ID"
+// @Param        status  body      models.ModerateReview  true  "Status"
+// @Success      200  {object}  models.Review
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/reviews/{review}/moderation [put]
+func ModerateAlbumReview(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	var moderation models.ModerateReview
+
+	if err := c.ShouldBindWith(&moderation, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	if validationErr := validate.Struct(moderation); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return
+	}
+
+	review, err := findReview(c, c.Param("id"), c.Param("review"))
+
+	if err == nil {
+		now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+		review.Status = moderation.Status
+		review.Moderated_at = &now
+
+		err = inTransaction(c, func(sc mongo.SessionContext) error {
+			return saveReview(sc, review, bson.M{"status": review.Status, "moderated_at": now})
+		})
+	}
+
+	if err == errReviewNotFound {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Println("review failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not save the review"})
+		return
+	}
+
+	respond(c, http.StatusOK, review)
+}
+
+// findReview finds a review of the album by their hex IDs
+func findReview(ctx context.Context, albumHex, reviewHex string) (models.Review, error) {
+	albumID, _ := primitive.ObjectIDFromHex(albumHex)
+	id, _ := primitive.ObjectIDFromHex(reviewHex)
+
+	var review models.Review
+
+	err := reviewsCollection.FindOne(ctx, bson.M{"_id": id, "album_id": albumID}).Decode(&review)
+
+	if err == mongo.ErrNoDocuments {
+		return review, errReviewNotFound
+	}
+
+	return review, err
+}
+
+// saveReview sets the fields of the review and brings the rating of its
+// album up to date
+func saveReview(sc mongo.SessionContext, review models.Review, set bson.M) error {
+	res, err := reviewsCollection.UpdateByID(sc, review.ID, bson.M{"$set": set})
+
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return errReviewNotFound
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	return updateAlbumRating(sc, review.AlbumID, now)
+}
+
+// updateAlbumRating recounts the rating of the album from its reviews that
+// aren't flagged. Run in the transaction changing the reviews, concurrent
+// changes conflict on the album and are retried.
+func updateAlbumRating(sc mongo.SessionContext, albumID primitive.ObjectID, now time.Time) error {
+	cursor, err := reviewsCollection.Aggregate(sc, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"album_id": albumID, "status": bson.M{"$ne": models.ReviewFlagged}}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": 1}, "sum": bson.M{"$sum": "$rating"}}}},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	var totals []struct {
+		Count int `bson:"count"`
+		Sum   int `bson:"sum"`
+	}
+
+	if err = cursor.All(sc, &totals); err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": now}, "$unset": bson.M{"rating": ""}}
+
+	if len(totals) > 0 && totals[0].Count > 0 {
+		update = bson.M{"$set": bson.M{
+			"updated_at": now,
+			"rating": models.Rating{
+				Average: math.Round(float64(totals[0].Sum)/float64(totals[0].Count)*100) / 100,
+				Count:   totals[0].Count,
+			},
+		}}
+	}
+
+	_, err = albumsCollection.UpdateByID(sc, albumID, update)
+	return err
+}
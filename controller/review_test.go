@@ -0,0 +1,156 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"rest/database"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReviewRoutes(t *testing.T) {
+
+	alice := asUser(t, "review-test-alice")
+	bob := asUser(t, "review-test-bob")
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Reviewed album", "artist": "Me Owais", "price": 10, "currency": "USD"}`, admin).Body.Bytes(), &postRes)
+	albumPath := "/albums/" + postRes.InsertedID
+
+	var review models.Review
+	json.Unmarshal(request("POST", albumPath+"/reviews", `{"rating": 5, "title": " Great "}`, alice).Body.Bytes(), &review)
+
+	assert.Equal(t, models.ReviewPublished, review.Status)
+	assert.Equal(t, "Great", review.Title)
+
+	reviewPath := albumPath + "/reviews/" + review.ID.Hex()
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:     "try to review anonymously",
+			method:   "POST",
+			path:     albumPath + "/reviews",
+			body:     `{"rating": 4}`,
+			response: `{"error":"X-User-ID header required"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:    "try to rate out of range",
+			method:  "POST",
+			path:    albumPath + "/reviews",
+			body:    `{"rating": 6}`,
+			headers: bob,
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to review twice",
+			method:   "POST",
+			path:     albumPath + "/reviews",
+			body:     `{"rating": 1}`,
+			headers:  alice,
+			response: `{"error":"you already reviewed this album"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:     "try to edit the review of someone else",
+			method:   "PATCH",
+			path:     reviewPath,
+			body:     `{"rating": 1}`,
+			headers:  bob,
+			response: `{"error":"review not found"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:    "try to moderate without the token",
+			method:  "PUT",
+			path:    reviewPath + "/moderation",
+			body:    `{"status": "flagged"}`,
+			headers: bob,
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "try to sort albums by something else",
+			method: "GET",
+			path:   "/albums?sort=price",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, tc.headers)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var bobs models.Review
+	json.Unmarshal(request("POST", albumPath+"/reviews", `{"rating": 2}`, bob).Body.Bytes(), &bobs)
+
+	rating := func() *models.Rating {
+		var album models.Album
+		json.Unmarshal(request("GET", albumPath, "", nil).Body.Bytes(), &album)
+		return album.Rating
+	}
+
+	assert.Equal(t, &models.Rating{Average: 3.5, Count: 2}, rating())
+
+	assert.Equal(t, http.StatusOK, request("PATCH", reviewPath, `{"rating": 4}`, alice).Code)
+	assert.Equal(t, &models.Rating{Average: 3, Count: 2}, rating())
+
+	// a flagged review is hidden and leaves the rating
+	assert.Equal(t, http.StatusOK, request("PUT", albumPath+"/reviews/"+bobs.ID.Hex()+"/moderation", `{"status": "flagged"}`, admin).Code)
+	assert.Equal(t, &models.Rating{Average: 4, Count: 1}, rating())
+	assert.Equal(t, http.StatusNotFound, request("GET", albumPath+"/reviews/"+bobs.ID.Hex(), "", alice).Code)
+	assert.Equal(t, http.StatusOK, request("GET", albumPath+"/reviews/"+bobs.ID.Hex(), "", bob).Code)
+
+	var reviews []models.Review
+	json.Unmarshal(request("GET", albumPath+"/reviews", "", nil).Body.Bytes(), &reviews)
+
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, review.ID, reviews[0].ID)
+	}
+
+	assert.Equal(t, http.StatusOK, request("DELETE", reviewPath, "", alice).Code)
+	assert.Nil(t, rating())
+
+	assert.Equal(t, http.StatusOK, request("DELETE", albumPath, "", admin).Code)
+}
+
+func TestBatchDeleteRemovesReviews(t *testing.T) {
+
+	carol := asUser(t, "review-test-carol")
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Bulk deleted album", "artist": "Me Owais", "price": 10, "currency": "USD"}`, admin).Body.Bytes(), &postRes)
+
+	assert.Equal(t, http.StatusOK, request("POST", "/albums/"+postRes.InsertedID+"/reviews", `{"rating": 4}`, carol).Code)
+
+	w := request("POST", "/albums:batchDelete", `{"ids": ["`+postRes.InsertedID+`"]}`, admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the album is gone, so the reviews can only be counted in the database
+	id, _ := primitive.ObjectIDFromHex(postRes.InsertedID)
+	reviews := database.OpenCollection(database.DBinstance(), "reviews")
+
+	n, err := reviews.CountDocuments(context.Background(), bson.M{"album_id": id})
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"rest/models"
+	"rest/search"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// albums ranked in memory when the text index finds nothing, the ones
+// sharing the most grams with the search terms
+const fuzzyCandidateLimit = 1000
+
+// fuzzy matches below this score are left out
+const minFuzzyScore = 0.5
+
+// SearchAlbums godoc
+// @Summary      Search albums
+// @Description  full-text search on title and artist, falling back to typo-tolerant matching when no word matches exactly. The typo-tolerant matching ranks the 1000 albums sharing the most letter trigrams with the terms.
+// @Tags         albums
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        q          query     string  true   "Search terms"
+// @Param        title      query     string  false  "Title contains"
+// @Param        artist     query     string  false  "Artist name"
+// @Param        min_price  query     number  false  "Minimum price"
+// @Param        max_price  query     number  false  "Maximum price"
+// @Param        page       query     int     false  "Page number, starting at 1"
+// @Param        limit      query     int     false  "Albums per page, at most 100"
+// @Param        facets     query     string  false  "Facets to count, any of artist,price_bucket,year. Wraps the hits in a models.FacetedSearch"
+// @Param        price_buckets  query  string  false  "Ascending price bucket boundaries, e.g. 0,10,20,50"
+// @Success      200  {array}   models.SearchHit
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/search [get]
+func SearchAlbums(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	q := strings.TrimSpace(c.Query("q"))
+	terms := search.Terms(q)
+
+	if len(terms) == 0 {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "missing search terms"})
+		return
+	}
+
+	filter, err := albumFilter(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	page, limit, _, err := pagination(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	facetNames, err := requestedFacets(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	buckets, err := priceBuckets(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	filter["$text"] = bson.M{"$search": q}
+
+	total, err := albumsCollection.CountDocuments(c, filter)
+
+	if err != nil {
+		log.Println("album search failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "search failed"})
+		return
+	}
+
+	var hits []models.SearchHit
+
+	if total > 0 {
+		score := bson.M{"$meta": "textScore"}
+		opts := options.Find().
+			SetProjection(bson.M{"score": score}).
+			SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+			SetSkip((page - 1) * limit).
+			SetLimit(limit)
+
+		cursor, err := albumsCollection.Find(c, filter, opts)
+
+		if err == nil {
+			err = cursor.All(c, &hits)
+		}
+
+		if err != nil {
+			log.Println("album search failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "search failed"})
+			return
+		}
+	} else {
+		// no word matched exactly, likely a typo
+		delete(filter, "$text")
+
+		if hits, err = fuzzySearch(c, filter, terms); err != nil {
+			log.Println("album search failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "search failed"})
+			return
+		}
+
+		// facets count every match, not only the page
+		ids := make([]primitive.ObjectID, len(hits))
+		for i, hit := range hits {
+			ids[i] = hit.ID
+		}
+		filter = bson.M{"_id": bson.M{"$in": ids}}
+
+		total = int64(len(hits))
+		hits = pageOf(hits, page, limit)
+	}
+
+	for i := range hits {
+		hits[i].Highlights = models.SearchHighlights{
+			Title:  search.Highlight(hits[i].Title, terms),
+			Artist: search.Highlight(hits[i].Artist, terms),
+		}
+	}
+
+	if hits == nil {
+		hits = []models.SearchHit{}
+	}
+
+	setTotalCount(c, total)
+
+	if facetNames != nil {
+		facets, err := albumFacets(c, filter, facetNames, buckets)
+
+		if err != nil {
+			log.Println("album search failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "search failed"})
+			return
+		}
+
+		respond(c, http.StatusOK, models.FacetedSearch{Hits: hits, Facets: facets})
+		return
+	}
+
+	respond(c, http.StatusOK, hits)
+}
+
+// fuzzySearch ranks the albums matching filter by their edit distance to the
+// search terms. The text index only matches whole (stemmed) words, so the
+// candidates are the albums sharing the most grams with the terms.
+func fuzzySearch(c *gin.Context, filter bson.M, terms []string) ([]models.SearchHit, error) {
+	grams := search.Grams(strings.Join(terms, " "))
+
+	filter["$or"] = bson.A{
+		bson.M{"title_grams": bson.M{"$in": grams}},
+		bson.M{"artist_grams": bson.M{"$in": grams}},
+	}
+
+	shared := func(field string) bson.M {
+		return bson.M{"$size": bson.M{"$setIntersection": bson.A{bson.M{"$ifNull": bson.A{field, bson.A{}}}, grams}}}
+	}
+
+	cursor, err := albumsCollection.Aggregate(c, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$addFields", Value: bson.M{"shared": bson.M{"$add": bson.A{shared("$title_grams"), shared("$artist_grams")}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "shared", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: fuzzyCandidateLimit}},
+		{{Key: "$project", Value: bson.M{"shared": 0}}},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(c)
+
+	var hits []models.SearchHit
+
+	for cursor.Next(c) {
+		var album models.Album
+
+		if err = cursor.Decode(&album); err != nil {
+			return nil, err
+		}
+
+		if score := search.Score(terms, album.Title+" "+album.Artist); score >= minFuzzyScore {
+			hits = append(hits, models.SearchHit{Album: album, Score: score})
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	return hits, nil
+}
+
+func pageOf(hits []models.SearchHit, page, limit int64) []models.SearchHit {
+	start := (page - 1) * limit
+
+	if start >= int64(len(hits)) {
+		return nil
+	}
+
+	end := start + limit
+	if end > int64(len(hits)) {
+		end = int64(len(hits))
+	}
+
+	return hits[start:end]
+}
+
+// MigrateSearchGrams gives the albums written before the fuzzy search had
+// grams their grams. It is safe to run repeatedly.
+func MigrateSearchGrams(ctx context.Context) (int, error) {
+	cursor, err := albumsCollection.Find(ctx, bson.M{"title_grams": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"title": 1, "artist": 1}))
+
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	var writes []mongo.WriteModel
+
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+
+		res, err := albumsCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		writes = writes[:0]
+
+		if res != nil {
+			migrated += int(res.ModifiedCount)
+		}
+
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var album models.Album
+
+		if err = cursor.Decode(&album); err != nil {
+			return migrated, err
+		}
+
+		setSearchGrams(&album)
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": album.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"title_grams": album.TitleGrams, "artist_grams": album.ArtistGrams}}))
+
+		if len(writes) == 500 {
+			if err = flush(); err != nil {
+				return migrated, err
+			}
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return migrated, err
+	}
+
+	return migrated, flush()
+}
+
+// setSearchGrams keeps the grams of the fuzzy search in step with the title
+// and the artist name
+func setSearchGrams(album *models.Album) {
+	album.TitleGrams = search.Grams(album.Title)
+	album.ArtistGrams = search.Grams(album.Artist)
+}
@@ -0,0 +1,106 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchAlbumsRoute(t *testing.T) {
+
+	test_cases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{
+			name:   "search albums",
+			query:  "?q=new+album",
+			status: http.StatusOK,
+		},
+		{
+			name:   "search albums with a typo",
+			query:  "?q=nwe+albmu&limit=5",
+			status: http.StatusOK,
+		},
+		{
+			name:   "try to search without terms",
+			query:  "?q=+",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "try to search with a wrong limit",
+			query:  "?q=album&limit=1000",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", apiprefix+"/albums/search"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.status == http.StatusOK {
+				var hits []models.SearchHit
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &hits))
+				assert.NotEmpty(t, w.Header().Get("X-Total-Count"))
+			}
+		})
+	}
+}
+
+func TestAlbumFacets(t *testing.T) {
+
+	test_cases := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{
+			name:   "list albums with facets",
+			path:   "/albums?facets=artist,price_bucket,year&price_buckets=0,5,15",
+			status: http.StatusOK,
+		},
+		{
+			name:   "search albums with facets",
+			path:   "/albums/search?q=album&facets=artist,price_bucket",
+			status: http.StatusOK,
+		},
+		{
+			name:   "try to count an unknown facet",
+			path:   "/albums?facets=label",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "try to count descending price buckets",
+			path:   "/albums/search?q=album&facets=price_bucket&price_buckets=20,10",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", apiprefix+tc.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.status == http.StatusOK {
+				var res struct {
+					Facets models.Facets `json:"facets"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+				assert.NotEmpty(t, res.Facets.Artist)
+				assert.NotEmpty(t, res.Facets.PriceBucket)
+			}
+		})
+	}
+}
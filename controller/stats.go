@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"net/http"
+	"rest/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAlbumStats godoc
+// @Summary      Catalog statistics
+// @Description  album counts, price statistics per currency and creations over time for the albums matching the list filters
+// @Tags         albums
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        title      query     string  false  "Title contains"
+// @Param        artist     query     string  false  "Artist name"
+// @Param        min_price  query     number  false  "Minimum price"
+// @Param        max_price  query     number  false  "Maximum price"
+// @Param        genre      query     string  false  "Genre ID, albums of its sub-genres match too"
+// @Param        tag        query     string  false  "Comma separated tags the albums all carry"
+// @Param        label      query     string  false  "Label ID"
+// @Param        format     query     string  false  "Release format, one of cd, vinyl or digital"
+// @Param        country    query     string  false  "ISO 3166-1 alpha-2 country of release"
+// @Param        catalog_number  query  string  false  "Catalog number"
+// @Param        barcode    query     string  false  "UPC or EAN barcode"
+// @Param        released_from  query  string  false  "Released on or after, 2006-01-02"
+// @Param        released_to    query  string  false  "Released on or before, 2006-01-02"
+// @Param        low_stock      query  bool    false  "Only the albums whose available stock dropped to their low stock threshold"
+// @Success      200  {object}  models.AlbumStats
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/stats [get]
+func GetAlbumStats(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	filter, err := albumFilter(c)
+
+	if err != nil {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: err.Error()})
+		return
+	}
+
+	stats, err := albumStats(c, filter)
+
+	if err != nil {
+		log.Println("album stats failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not compute statistics"})
+		return
+	}
+
+	respond(c, http.StatusOK, stats)
+}
+
+func albumStats(ctx context.Context, filter bson.M) (*models.AlbumStats, error) {
+	perPeriod := func(format string) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{
+				"_id":   bson.M{"$dateToString": bson.M{"format": format, "date": "$created_at"}},
+				"count": bson.M{"$sum": 1},
+			}},
+			bson.M{"$sort": bson.M{"_id": 1}},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "count"}},
+			"artists": bson.A{
+				bson.M{"$group": bson.M{"_id": "$artist", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			// prices of different currencies don't compare
+			"prices": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$ifNull": bson.A{"$currency", defaultCurrency}},
+					"count": bson.M{"$sum": 1},
+					"min":   bson.M{"$min": "$price"},
+					"max":   bson.M{"$max": "$price"},
+					"avg":   bson.M{"$avg": "$price"},
+				}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"per_day":   perPeriod("%Y-%m-%d"),
+			"per_week":  perPeriod("%G-W%V"),
+			"per_month": perPeriod("%Y-%m"),
+		}}},
+	}
+
+	cursor, err := albumsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		Artists []struct {
+			ID    string `bson:"_id"`
+			Count int    `bson:"count"`
+		} `bson:"artists"`
+		Prices   []models.PriceStats  `bson:"prices"`
+		PerDay   []models.PeriodCount `bson:"per_day"`
+		PerWeek  []models.PeriodCount `bson:"per_week"`
+		PerMonth []models.PeriodCount `bson:"per_month"`
+	}
+
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	stats := &models.AlbumStats{
+		Artists: []models.FacetCount{},
+		Prices:  []models.PriceStats{},
+		Created: models.CreationStats{
+			PerDay:   []models.PeriodCount{},
+			PerWeek:  []models.PeriodCount{},
+			PerMonth: []models.PeriodCount{},
+		},
+	}
+
+	if len(results) == 0 || len(results[0].Total) == 0 {
+		return stats, nil
+	}
+
+	r := results[0]
+
+	stats.Total = r.Total[0].Count
+	stats.Created = models.CreationStats{PerDay: r.PerDay, PerWeek: r.PerWeek, PerMonth: r.PerMonth}
+
+	for _, artist := range r.Artists {
+		stats.Artists = append(stats.Artists, models.FacetCount{Value: artist.ID, Count: artist.Count})
+	}
+
+	for _, price := range r.Prices {
+		if price.Median, err = medianPrice(ctx, filter, price.Currency, price.Count); err != nil {
+			return nil, err
+		}
+
+		stats.Prices = append(stats.Prices, price)
+	}
+
+	return stats, nil
+}
+
+// medianPrice reads the one or two middle prices of the n albums of currency
+// matching filter, instead of pulling every price into a single aggregation
+// document
+func medianPrice(ctx context.Context, filter bson.M, currency string, n int) (models.Amount, error) {
+	inCurrency := bson.M{"currency": currency}
+	for key, value := range filter {
+		inCurrency[key] = value
+	}
+
+	// albums without a currency are in the default one
+	if currency == defaultCurrency {
+		inCurrency["currency"] = bson.M{"$in": bson.A{currency, nil}}
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"price": 1}).
+		SetSkip(int64((n - 1) / 2)).
+		SetLimit(int64(2 - n%2)).
+		SetProjection(bson.M{"price": 1})
+
+	cursor, err := albumsCollection.Find(ctx, inCurrency, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var middle []models.Album
+
+	if err = cursor.All(ctx, &middle); err != nil || len(middle) == 0 {
+		return 0, err
+	}
+
+	// the mean of the middle prices is worked out on their exact decimals and
+	// rounded to the minor unit of the currency
+	sum := new(big.Rat)
+	for _, album := range middle {
+		sum.Add(sum, album.Price.Decimal().Rat())
+	}
+
+	sum.Quo(sum, big.NewRat(int64(len(middle)), 1))
+	median, err := strconv.ParseFloat(sum.FloatString(currencyPlaces(currency)), 64)
+
+	return models.Amount(median), err
+}
@@ -0,0 +1,60 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumStatsRoute(t *testing.T) {
+
+	test_cases := []struct {
+		name   string
+		query  string
+		empty  bool
+		status int
+	}{
+		{
+			name:   "get catalog statistics",
+			status: http.StatusOK,
+		},
+		{
+			name:   "get statistics of albums nobody sells",
+			query:  "?min_price=1000000",
+			empty:  true,
+			status: http.StatusOK,
+		},
+		{
+			name:   "try to get statistics with a wrong filter",
+			query:  "?max_price=cheap",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", apiprefix+"/albums/stats"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.status == http.StatusOK {
+				var stats models.AlbumStats
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+				assert.Equal(t, tc.empty, stats.Total == 0)
+
+				if !tc.empty && assert.NotEmpty(t, stats.Prices) {
+					for _, price := range stats.Prices {
+						assert.LessOrEqual(t, price.Min, price.Median)
+						assert.LessOrEqual(t, price.Median, price.Max)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+	"rest/database"
+	"rest/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 50
+)
+
+// SuggestAlbums godoc
+// @Summary      Suggest artists or titles
+// @Description  distinct artists or titles starting with the prefix, ignoring case and accents, most frequent first
+// @Tags         albums
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        prefix  query     string  true   "Start of the artist or title"
+// @Param        field   query     string  false  "artist (default) or title"
+// @Param        limit   query     int     false  "Number of suggestions, at most 50"
+// @Success      200  {array}   models.Suggestion
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/albums/suggest [get]
+func SuggestAlbums(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	prefix := strings.TrimSpace(c.Query("prefix"))
+
+	if prefix == "" {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "missing prefix"})
+		return
+	}
+
+	field := c.DefaultQuery("field", "artist")
+
+	if field != "artist" && field != "title" {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "field must be artist or title"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSuggestions)))
+
+	if err != nil || limit < 1 || limit > maxSuggestions {
+		respond(c, http.StatusBadRequest, models.ErrorMessage{Error: "invalid limit"})
+		return
+	}
+
+	// U+FFFF sorts after every other character under the collation,
+	// so the range holds exactly the values starting with prefix
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{field: bson.M{"$gte": prefix, "$lt": prefix + "\uffff"}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$" + field, "value": bson.M{"$first": "$" + field}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "value": 1, "count": 1}}},
+	}
+
+	cursor, err := albumsCollection.Aggregate(c, pipeline, options.Aggregate().SetCollation(database.SuggestCollation))
+
+	suggestions := []models.Suggestion{}
+
+	if err == nil {
+		err = cursor.All(c, &suggestions)
+	}
+
+	if err != nil {
+		log.Println("album suggestions failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load suggestions"})
+		return
+	}
+
+	respond(c, http.StatusOK, suggestions)
+}
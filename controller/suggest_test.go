@@ -0,0 +1,57 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestAlbumsRoute(t *testing.T) {
+
+	test_cases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{
+			name:   "suggest artists",
+			query:  "?prefix=me",
+			status: http.StatusOK,
+		},
+		{
+			name:   "suggest titles ignoring case and accents",
+			query:  "?prefix=N%C3%89W&field=title&limit=5",
+			status: http.StatusOK,
+		},
+		{
+			name:   "try to suggest without a prefix",
+			query:  "?field=title",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "try to suggest an unknown field",
+			query:  "?prefix=me&field=price",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", apiprefix+"/albums/suggest"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.status == http.StatusOK {
+				var suggestions []models.Suggestion
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &suggestions))
+				assert.NotEmpty(t, suggestions)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetTags godoc
+// @Summary      Get tags
+// @Description  get the tags in use with the number of albums carrying them, most used first
+// @Tags         tags
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Success      200  {array}   models.TagCount
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/tags [get]
+func GetTags(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	cursor, err := albumsCollection.Aggregate(c, mongo.Pipeline{
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	})
+
+	if err != nil {
+		log.Println("tag list failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not list the tags"})
+		return
+	}
+
+	tags := []models.TagCount{}
+
+	if err = cursor.All(c, &tags); err != nil {
+		log.Println("tag list failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not list the tags"})
+		return
+	}
+
+	respond(c, http.StatusOK, tags)
+}
+
+// RenameTag godoc
+// @Summary      Rename a tag
+// @Description  rename a tag on every album at once, albums that already have the new name keep it once
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        rename  body      models.RenameTag  true  "Rename Tag"
+// @Success      200	{object}  models.MergeTagsResult
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/tags:rename [post]
+func RenameTag(c *gin.Context) {
+	var req models.RenameTag
+
+	if !bindTagRequest(c, &req) {
+		return
+	}
+
+	mergeTagsResponse(c, []string{req.From}, req.To)
+}
+
+// MergeTags godoc
+// @Summary      Merge tags
+// @Description  replace several tags with one on every album at once
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        merge  body      models.MergeTags  true  "Merge Tags"
+// @Success      200	{object}  models.MergeTagsResult
+// @Failure      422	{object}  models.ErrorMessage
+// @Failure      500	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/tags:merge [post]
+func MergeTags(c *gin.Context) {
+	var req models.MergeTags
+
+	if !bindTagRequest(c, &req) {
+		return
+	}
+
+	mergeTagsResponse(c, req.From, req.To)
+}
+
+func bindTagRequest(c *gin.Context, req interface{}) bool {
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return false
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return false
+	}
+
+	if validationErr := validate.Struct(req); validationErr != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return false
+	}
+
+	return true
+}
+
+func mergeTagsResponse(c *gin.Context, from []string, to string) {
+	if to = normalizeTag(to); to == "" {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorMessage{Error: "the new tag is empty"})
+		return
+	}
+
+	albums, err := mergeTags(c, normalizeTags(from), to)
+
+	if err != nil {
+		log.Println("tag merge failed:", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorMessage{Error: "could not update the tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MergeTagsResult{Albums: albums})
+}
+
+// mergeTags replaces the from tags with to on every album in one
+// transaction, so no album is seen with half of the change. It returns the
+// number of albums changed.
+func mergeTags(ctx context.Context, from []string, to string) (int64, error) {
+	var sources []string
+
+	for _, tag := range from {
+		if tag != to {
+			sources = append(sources, tag)
+		}
+	}
+
+	if len(sources) == 0 {
+		return 0, nil
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+	tagged := bson.M{"tags": bson.M{"$in": sources}}
+
+	var albums int64
+
+	err := inTransaction(ctx, func(sc mongo.SessionContext) error {
+		res, err := albumsCollection.UpdateMany(sc, tagged, bson.M{
+			"$addToSet": bson.M{"tags": to},
+			"$set":      bson.M{"updated_at": now},
+		})
+
+		if err != nil {
+			return err
+		}
+
+		albums = res.MatchedCount
+
+		_, err = albumsCollection.UpdateMany(sc, tagged, bson.M{"$pull": tagged})
+
+		return err
+	})
+
+	return albums, err
+}
+
+// tags are compared and stored in lower case without surrounding spaces
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags normalizes every tag and drops repeated ones
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
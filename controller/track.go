@@ -0,0 +1,323 @@
+package controller
+
+import (
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAlbumTracks godoc
+// @Summary      Get the tracks of an album
+// @Description  get the tracks of an album ordered by track number
+// @Tags         tracks
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Album ID"
+// @Success      200  {array}   models.Track
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/tracks [get]
+func GetAlbumTracks(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	tracks := album.Tracks
+	if tracks == nil {
+		tracks = []models.Track{}
+	}
+
+	respond(c, http.StatusOK, models.Tracks(tracks))
+}
+
+// GetAlbumTrack godoc
+// @Summary      Get a track
+// @Description  get a track of an album by track ID
+// @Tags         tracks
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string  true  "Album ID"
+// @Param        track  path      string  true  "Track ID"
+// @Success      200  {object}  models.Track
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/tracks/{track} [get]
+func GetAlbumTrack(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := trackIndex(album.Tracks, c.Param("track"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "track not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, album.Tracks[i])
+}
+
+// PostAlbumTrack godoc
+// @Summary      Add a track
+// @Description  add a track to an album, the album duration follows
+// @Tags         tracks
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string           true  "Album ID"
+// @Param        track  body      models.AddTrack  true  "Add Track"
+// @Success      200	{object}  models.Track
+// @Failure      404	{object}  models.ErrorMessage
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/tracks [post]
+func PostAlbumTrack(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	var add models.AddTrack
+
+	if err := c.ShouldBindWith(&add, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	previous := album.Tracks
+	track := newTrack(add)
+	album.Tracks = append(append([]models.Track{}, previous...), track)
+
+	if !saveTracks(c, &album, previous) {
+		return
+	}
+
+	respond(c, http.StatusOK, album.Tracks[trackIndex(album.Tracks, track.ID.Hex())])
+}
+
+// UpdateAlbumTrack godoc
+// @Summary      Update a track
+// @Description  update a track of an album by json
+// @Tags         tracks
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string           true  "Album ID"
+// @Param        track  path      string           true  "Track ID"
+// @Param        body   body      models.AddTrack  true  "Update Track"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Failure      415      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/tracks/{track} [patch]
+func UpdateAlbumTrack(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := trackIndex(album.Tracks, c.Param("track"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "track not found"})
+		return
+	}
+
+	previous := album.Tracks
+	album.Tracks = append([]models.Track{}, previous...)
+	track := &album.Tracks[i]
+	id := track.ID
+
+	if err := c.ShouldBindWith(track, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	track.ID = id
+
+	if !saveTracks(c, &album, previous) {
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the track"})
+}
+
+// DeleteAlbumTrack godoc
+// @Summary      Delete a track
+// @Description  remove a track from an album by track ID
+// @Tags         tracks
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id     path      string  true  "Album ID"
+// @Param        track  path      string  true  "Track ID"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/tracks/{track} [delete]
+func DeleteAlbumTrack(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := trackIndex(album.Tracks, c.Param("track"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "track not found"})
+		return
+	}
+
+	previous := album.Tracks
+	album.Tracks = append(append([]models.Track{}, previous[:i]...), previous[i+1:]...)
+
+	if !saveTracks(c, &album, previous) {
+		return
+	}
+
+	deleteOrphanPreviews(c, album)
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the track"})
+}
+
+func findTrackAlbum(c *gin.Context) (models.Album, bool) {
+	id, _ := primitive.ObjectIDFromHex(c.Param("id"))
+
+	var album models.Album
+
+	if err := albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album); err != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return album, false
+	}
+
+	return album, true
+}
+
+func trackIndex(tracks []models.Track, hex string) int {
+	id, err := primitive.ObjectIDFromHex(hex)
+
+	if err != nil {
+		return -1
+	}
+
+	for i, track := range tracks {
+		if track.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func newTrack(add models.AddTrack) models.Track {
+	return models.Track{
+		Number:   add.Number,
+		Title:    add.Title,
+		Duration: add.Duration,
+		ISRC:     add.ISRC,
+		Explicit: add.Explicit,
+	}
+}
+
+// prepareTracks gives new tracks an id, normalizes their ISRC, orders them by
+// number and recomputes the album duration
+func prepareTracks(album *models.Album) {
+	album.Duration = 0
+
+	for i := range album.Tracks {
+		track := &album.Tracks[i]
+
+		if track.ID.IsZero() {
+			track.ID = primitive.NewObjectID()
+		}
+
+		track.ISRC = normalizeISRC(track.ISRC)
+		album.Duration += track.Duration
+	}
+
+	sort.SliceStable(album.Tracks, func(i, j int) bool {
+		return album.Tracks[i].Number < album.Tracks[j].Number
+	})
+}
+
+// saveTracks validates and stores the new track list of the album. It only
+// writes when the album still has the previous tracks, so concurrent changes
+// to the same album don't overwrite each other.
+func saveTracks(c *gin.Context, album *models.Album, previous []models.Track) bool {
+	prepareTracks(album)
+
+	if validationErr := validate.Struct(album); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return false
+	}
+
+	album.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	res, err := albumsCollection.UpdateOne(c,
+		bson.M{"_id": album.ID, "tracks": previous},
+		bson.M{"$set": bson.M{"tracks": album.Tracks, "duration": album.Duration, "updated_at": album.Updated_at}})
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not save the tracks"})
+		return false
+	}
+
+	if res.MatchedCount == 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "the tracks changed meanwhile, try again"})
+		return false
+	}
+
+	return true
+}
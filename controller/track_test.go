@@ -0,0 +1,108 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlbumTrackRoutes(t *testing.T) {
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", apiprefix+"/albums", bytes.NewBufferString(`{
+		"title": "Track album",
+		"artist": "Me Owais",
+		"price": 10,
+		"tracks": [{"number": 1, "title": "Opening", "duration": 200}]
+	}`))
+	req.Header.Add("Authorization", "owais")
+	router.ServeHTTP(w, req)
+
+	var postRes PostResponse
+	json.Unmarshal(w.Body.Bytes(), &postRes)
+	albumID := postRes.InsertedID
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		body     string
+		response string
+		status   int
+	}{
+		{
+			name:   "add a track with a hyphenated isrc",
+			method: "POST",
+			body:   `{"number": 2, "title": "Second", "duration": 100, "isrc": "us-rc1-76-07839", "explicit": true}`,
+			status: http.StatusOK,
+		},
+		{
+			name:     "try to add a track with a malformed isrc",
+			method:   "POST",
+			body:     `{"number": 3, "title": "Third", "duration": 100, "isrc": "USRC1760783"}`,
+			response: `{"error":"Key: 'Album.Tracks[2].ISRC' Error:Field validation for 'ISRC' failed on the 'isrc' tag"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "try to add a track with a number that is taken",
+			method:   "POST",
+			body:     `{"number": 1, "title": "Again", "duration": 100}`,
+			response: `{"error":"Key: 'Album.Tracks' Error:Field validation for 'Tracks' failed on the 'unique' tag"}`,
+			status:   http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, apiprefix+"/albums/"+albumID+"/tracks", bytes.NewBufferString(tc.body))
+			req.Header.Add("Authorization", "owais")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("the album duration is the sum of its tracks", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", apiprefix+"/albums/"+albumID, nil)
+		router.ServeHTTP(w, req)
+
+		var album models.Album
+		json.Unmarshal(w.Body.Bytes(), &album)
+
+		assert.Equal(t, 300, album.Duration)
+
+		if assert.Len(t, album.Tracks, 2) {
+			assert.Equal(t, "USRC17607839", album.Tracks[1].ISRC)
+
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest("DELETE", apiprefix+"/albums/"+albumID+"/tracks/"+album.Tracks[1].ID.Hex(), nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", apiprefix+"/albums/"+albumID, nil)
+		router.ServeHTTP(w, req)
+
+		json.Unmarshal(w.Body.Bytes(), &album)
+
+		assert.Equal(t, 200, album.Duration)
+	})
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", apiprefix+"/albums/"+albumID, nil)
+	router.ServeHTTP(w, req)
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"regexp"
+	"rest/models"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// country code, registrant code, year of reference and designation code
+var isrcPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}[0-9]{7}$`)
+
+// registerValidators adds the validation tags of the models that the
+// validator doesn't know
+func registerValidators(v *validator.Validate) {
+	v.RegisterValidation("isrc", func(fl validator.FieldLevel) bool {
+		return isrcPattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("gtin", func(fl validator.FieldLevel) bool {
+		return validGTIN(fl.Field().String())
+	})
+	v.RegisterStructValidation(validateAlbum, models.Album{})
+	v.RegisterStructValidation(validatePromotion, models.Promotion{})
+}
+
+// validateAlbum checks the rules that span several fields of an album
+func validateAlbum(sl validator.StructLevel) {
+	album := sl.Current().Interface().(models.Album)
+
+	// the price range of an album is only meaningful in a single currency
+	for _, variant := range album.Variants {
+		if variant.Currency != album.Currency {
+			sl.ReportError(album.Variants, "Variants", "Variants", "currency", "")
+			return
+		}
+	}
+}
+
+// validatePromotion checks the fields each type of promotion needs
+func validatePromotion(sl validator.StructLevel) {
+	promotion := sl.Current().Interface().(models.Promotion)
+
+	switch promotion.Type {
+	case models.PromotionPercent:
+		if promotion.Value <= 0 || promotion.Value > 100 {
+			sl.ReportError(promotion.Value, "Value", "Value", "percent", "")
+		}
+	case models.PromotionFixed:
+		if promotion.Value <= 0 {
+			sl.ReportError(promotion.Value, "Value", "Value", "gt", "0")
+		}
+	case models.PromotionBOGO:
+		if promotion.Buy < 1 {
+			sl.ReportError(promotion.Buy, "Buy", "Buy", "min", "1")
+		}
+		if promotion.Get < 1 {
+			sl.ReportError(promotion.Get, "Get", "Get", "min", "1")
+		}
+	}
+
+	if promotion.Starts_at != nil && promotion.Ends_at != nil && !promotion.Ends_at.After(*promotion.Starts_at) {
+		sl.ReportError(promotion.Ends_at, "Ends_at", "Ends_at", "gtfield", "Starts_at")
+	}
+}
+
+// normalizeISRC turns the written form of an ISRC, e.g. us-rc1-76-07839,
+// into the stored one, USRC17607839
+func normalizeISRC(isrc string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(isrc), "-", ""))
+}
+
+// validGTIN tells whether code is an EAN-8, UPC-A or EAN-13 barcode with a
+// correct check digit
+func validGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13:
+	default:
+		return false
+	}
+
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+
+	sum := 0
+
+	// from the right, the digits before the check digit weigh 3, 1, 3, ...
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+
+		if (len(code)-i)%2 == 0 {
+			digit *= 3
+		}
+
+		sum += digit
+	}
+
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+// normalizeBarcode drops the spaces and hyphens barcodes are often printed with
+func normalizeBarcode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
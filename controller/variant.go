@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"net/http"
+	"rest/middlewares"
+	"rest/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetAlbumVariants godoc
+// @Summary      Get the variants of an album
+// @Description  get the formats an album is sold in, each with its SKU, price and stock
+// @Tags         variants
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id   path      string  true  "Album ID"
+// @Success      200  {array}   models.Variant
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/variants [get]
+func GetAlbumVariants(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	variants := album.Variants
+	if variants == nil {
+		variants = []models.Variant{}
+	}
+
+	respond(c, http.StatusOK, models.Variants(variants))
+}
+
+// GetAlbumVariant godoc
+// @Summary      Get a variant
+// @Description  get a variant of an album by variant ID
+// @Tags         variants
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id       path      string  true  "Album ID"
+// @Param        variant  path      string  true  "Variant ID"
+// @Success      200  {object}  models.Variant
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/variants/{variant} [get]
+func GetAlbumVariant(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := variantIndex(album.Variants, c.Param("variant"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "variant not found"})
+		return
+	}
+
+	respond(c, http.StatusOK, album.Variants[i])
+}
+
+// PostAlbumVariant godoc
+// @Summary      Add a variant
+// @Description  add a variant to an album, the album price range follows
+// @Tags         variants
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id       path      string             true  "Album ID"
+// @Param        variant  body      models.AddVariant  true  "Add Variant"
+// @Success      200	{object}  models.Variant
+// @Failure      404	{object}  models.ErrorMessage
+// @Failure      409	{object}  models.ErrorMessage
+// @Failure      415	{object}  models.ErrorMessage
+// @Failure      422	{object}  models.ErrorMessage
+// @Security     bearer
+// @Router       /v1/albums/{id}/variants [post]
+func PostAlbumVariant(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	if !middlewares.IsValidToken(c.GetHeader("Authorization")) {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "wrong token"})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	var add models.AddVariant
+
+	if err := c.ShouldBindWith(&add, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	previous := album.Variants
+	variant := models.Variant{
+		ID:       primitive.NewObjectID(),
+		SKU:      add.SKU,
+		Format:   add.Format,
+		Price:    models.Amount(add.Price),
+		Currency: add.Currency,
+		Stock:    add.Stock,
+	}
+	album.Variants = append(append([]models.Variant{}, previous...), variant)
+
+	if !saveVariants(c, &album, previous) {
+		return
+	}
+
+	respond(c, http.StatusOK, album.Variants[variantIndex(album.Variants, variant.ID.Hex())])
+}
+
+// UpdateAlbumVariant godoc
+// @Summary      Update a variant
+// @Description  update a variant of an album by json
+// @Tags         variants
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id       path      string             true  "Album ID"
+// @Param        variant  path      string             true  "Variant ID"
+// @Param        body     body      models.AddVariant  true  "Update Variant"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Failure      415      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/variants/{variant} [patch]
+func UpdateAlbumVariant(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := variantIndex(album.Variants, c.Param("variant"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "variant not found"})
+		return
+	}
+
+	previous := album.Variants
+	album.Variants = append([]models.Variant{}, previous...)
+	variant := &album.Variants[i]
+	id := variant.ID
+
+	if err := c.ShouldBindWith(variant, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return
+	}
+
+	variant.ID = id
+
+	if !saveVariants(c, &album, previous) {
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully updated the variant"})
+}
+
+// DeleteAlbumVariant godoc
+// @Summary      Delete a variant
+// @Description  remove a variant from an album by variant ID
+// @Tags         variants
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        id       path      string  true  "Album ID"
+// @Param        variant  path      string  true  "Variant ID"
+// @Success      200      {object}  models.SuccessMessage
+// @Failure      404      {object}  models.ErrorMessage
+// @Failure      409      {object}  models.ErrorMessage
+// @Failure      422      {object}  models.ErrorMessage
+// @Router       /v1/albums/{id}/variants/{variant} [delete]
+func DeleteAlbumVariant(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	album, ok := findTrackAlbum(c)
+
+	if !ok {
+		return
+	}
+
+	i := variantIndex(album.Variants, c.Param("variant"))
+
+	if i < 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "variant not found"})
+		return
+	}
+
+	previous := album.Variants
+	album.Variants = append(append([]models.Variant{}, previous[:i]...), previous[i+1:]...)
+
+	if !saveVariants(c, &album, previous) {
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully deleted the variant"})
+}
+
+func variantIndex(variants []models.Variant, hex string) int {
+	id, err := primitive.ObjectIDFromHex(hex)
+
+	if err != nil {
+		return -1
+	}
+
+	for i, variant := range variants {
+		if variant.ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// prepareVariants gives new variants an id, normalizes their codes and
+// derives the price range of the album. The album price and currency follow
+// the cheapest variant so price filters and sorting keep working.
+func prepareVariants(album *models.Album) {
+	album.PriceRange = nil
+
+	for i := range album.Variants {
+		variant := &album.Variants[i]
+
+		if variant.ID.IsZero() {
+			variant.ID = primitive.NewObjectID()
+		}
+
+		variant.SKU = strings.TrimSpace(variant.SKU)
+		variant.Format = strings.ToLower(strings.TrimSpace(variant.Format))
+		variant.Currency = strings.ToUpper(strings.TrimSpace(variant.Currency))
+
+		switch {
+		case album.PriceRange == nil:
+			album.PriceRange = &models.PriceRange{Min: variant.Price, Max: variant.Price, Currency: variant.Currency}
+		case variant.Price.Cmp(album.PriceRange.Min) < 0:
+			album.PriceRange.Min = variant.Price
+		case variant.Price.Cmp(album.PriceRange.Max) > 0:
+			album.PriceRange.Max = variant.Price
+		}
+	}
+
+	if album.PriceRange != nil {
+		album.Price = album.PriceRange.Min
+		album.Currency = album.PriceRange.Currency
+	}
+}
+
+// saveVariants validates and stores the new variant list of the album. Like
+// saveTracks it only writes when the album still has the previous variants.
+func saveVariants(c *gin.Context, album *models.Album, previous []models.Variant) bool {
+	prepareVariants(album)
+
+	if validationErr := validate.Struct(album); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return false
+	}
+
+	album.Updated_at, _ = time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	res, err := albumsCollection.UpdateOne(c,
+		bson.M{"_id": album.ID, "variants": previous},
+		bson.M{"$set": bson.M{
+			"variants":    album.Variants,
+			"price":       album.Price,
+			"currency":    album.Currency,
+			"price_range": album.PriceRange,
+			"updated_at":  album.Updated_at,
+		}})
+
+	if mongo.IsDuplicateKeyError(err) {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "sku already exists"})
+		return false
+	}
+
+	if err != nil {
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not save the variants"})
+		return false
+	}
+
+	if res.MatchedCount == 0 {
+		respond(c, http.StatusConflict, models.ErrorMessage{Error: "the variants changed meanwhile, try again"})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,99 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariantRoutes(t *testing.T) {
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Variant album", "artist": "Me Owais", "price": 10}`, admin).Body.Bytes(), &postRes)
+
+	variantsPath := "/albums/" + postRes.InsertedID + "/variants"
+
+	var vinyl models.Variant
+	json.Unmarshal(request("POST", variantsPath, `{"sku": "VAR-TEST-LP", "format": "vinyl", "price": 24.99, "currency": "eur", "stock": 3}`, admin).Body.Bytes(), &vinyl)
+
+	assert.Equal(t, "EUR", vinyl.Currency)
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		response string
+		status   int
+	}{
+		{
+			name:   "add a second variant",
+			method: "POST",
+			path:   variantsPath,
+			body:   `{"sku": "VAR-TEST-CD", "format": "cd", "price": 12.5, "currency": "EUR", "stock": 10}`,
+			status: http.StatusOK,
+		},
+		{
+			name:     "try to reuse a sku",
+			method:   "POST",
+			path:     variantsPath,
+			body:     `{"sku": "VAR-TEST-LP", "format": "digital", "price": 8, "currency": "EUR"}`,
+			response: `{"error":"sku already exists"}`,
+			status:   http.StatusConflict,
+		},
+		{
+			name:   "try to price a variant in another currency",
+			method: "POST",
+			path:   variantsPath,
+			body:   `{"sku": "VAR-TEST-MP3", "format": "digital", "price": 8, "currency": "USD"}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "try to add a variant with negative stock",
+			method: "POST",
+			path:   variantsPath,
+			body:   `{"sku": "VAR-TEST-MP3", "format": "digital", "price": 8, "currency": "EUR", "stock": -1}`,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "update a variant",
+			method:   "PATCH",
+			path:     variantsPath + "/" + vinyl.ID.Hex(),
+			body:     `{"price": 29.99}`,
+			response: `{"message":"successfully updated the variant"}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "try to update an unknown variant",
+			method:   "PATCH",
+			path:     variantsPath + "/000000000000000000000001",
+			body:     `{"price": 29.99}`,
+			response: `{"error":"variant not found"}`,
+			status:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, admin)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	var album models.Album
+	json.Unmarshal(request("GET", "/albums/"+postRes.InsertedID, "", admin).Body.Bytes(), &album)
+
+	assert.Len(t, album.Variants, 2)
+	assert.Equal(t, &models.PriceRange{Min: 12.5, Max: 29.99, Currency: "EUR"}, album.PriceRange)
+	assert.Equal(t, models.Amount(12.5), album.Price)
+
+	assert.Equal(t, http.StatusOK, request("DELETE", "/albums/"+postRes.InsertedID, "", admin).Code)
+}
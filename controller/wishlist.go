@@ -0,0 +1,405 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"rest/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetWishlist godoc
+// @Summary      Get the wishlist
+// @Description  get the albums the user saved for later, at their current prices
+// @Tags         wishlist
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string  true  "Token signed for the user, see /users/{id}/token"
+// @Success      200  {object}  models.WishlistView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/wishlist [get]
+func GetWishlist(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	respondWishlist(c, user)
+}
+
+// PostWishlistItem godoc
+// @Summary      Save an album for later
+// @Description  put an album in the wishlist of the user, nothing changes when it is there already
+// @Tags         wishlist
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string                  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string                  true  "Token signed for the user, see /users/{id}/token"
+// @Param        item          body      models.AddWishlistItem  true  "Wishlist Item"
+// @Success      200  {object}  models.WishlistView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/wishlist/items [post]
+func PostWishlistItem(c *gin.Context) {
+	var add models.AddWishlistItem
+
+	user, ok := bindWishlistRequest(c, &add)
+
+	if !ok {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(add.AlbumID)
+
+	var album models.Album
+
+	if err != nil || albumsCollection.FindOne(c, bson.M{"_id": id}).Decode(&album) != nil {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not found"})
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	item := models.WishlistItem{AlbumID: id, Price: album.Price, Currency: album.Currency, Added_at: now}
+
+	if item.Currency == "" {
+		item.Currency = defaultCurrency
+	}
+
+	_, err = wishlistsCollection.UpdateOne(c,
+		bson.M{"user_id": user, "items.album_id": bson.M{"$ne": id}},
+		bson.M{
+			"$push":        bson.M{"items": item},
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "public": false, "created_at": now},
+		},
+		options.Update().SetUpsert(true))
+
+	// when the wishlist holds the album already the filter misses it and the
+	// upsert clashes with it on the user
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		log.Println("wishlist failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not save the album"})
+		return
+	}
+
+	respondWishlist(c, user)
+}
+
+// DeleteWishlistItem godoc
+// @Summary      Remove an album from the wishlist
+// @Description  take an album out of the wishlist of the user
+// @Tags         wishlist
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string  true  "Token signed for the user, see /users/{id}/token"
+// @Param        album         path      string  true  "Album ID"
+// @Success      200  {object}  models.WishlistView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/wishlist/items/{album} [delete]
+func DeleteWishlistItem(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	id, _ := primitive.ObjectIDFromHex(c.Param("album"))
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	res, err := wishlistsCollection.UpdateOne(c,
+		bson.M{"user_id": user, "items.album_id": id},
+		bson.M{"$pull": bson.M{"items": bson.M{"album_id": id}}, "$set": bson.M{"updated_at": now}})
+
+	if err != nil {
+		log.Println("wishlist failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not remove the album"})
+		return
+	}
+
+	if res.MatchedCount == 0 {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "album not in the wishlist"})
+		return
+	}
+
+	respondWishlist(c, user)
+}
+
+// DeleteWishlist godoc
+// @Summary      Empty the wishlist
+// @Description  remove every album from the wishlist of the user, and stop sharing it
+// @Tags         wishlist
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string  true  "Token signed for the user, see /users/{id}/token"
+// @Success      200  {object}  models.SuccessMessage
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/wishlist [delete]
+func DeleteWishlist(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return
+	}
+
+	if _, err := wishlistsCollection.DeleteOne(c, bson.M{"user_id": user}); err != nil {
+		log.Println("wishlist failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not empty the wishlist"})
+		return
+	}
+
+	respond(c, http.StatusOK, models.SuccessMessage{Message: "successfully emptied the wishlist"})
+}
+
+// PutWishlistSharing godoc
+// @Summary      Share the wishlist
+// @Description  make the wishlist of the user public, readable by anyone with its share_token at /wishlists/{token}, or private again. Sharing again after that gives a new token, the old link stops working.
+// @Tags         wishlist
+// @Accept       json,xml,application/x-yaml,application/x-msgpack
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        X-User-ID     header    string                  true  "ID of the signed in user"
+// @Param        X-User-Token  header    string                  true  "Token signed for the user, see /users/{id}/token"
+// @Param        sharing       body      models.WishlistSharing  true  "Sharing"
+// @Success      200  {object}  models.WishlistView
+// @Failure      400  {object}  models.ErrorMessage
+// @Failure      401  {object}  models.ErrorMessage
+// @Failure      415  {object}  models.ErrorMessage
+// @Failure      422  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/wishlist/sharing [put]
+func PutWishlistSharing(c *gin.Context) {
+	var sharing models.WishlistSharing
+
+	user, ok := bindWishlistRequest(c, &sharing)
+
+	if !ok {
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	filter := bson.M{"user_id": user}
+	update := bson.M{
+		"$set":         bson.M{"public": false, "updated_at": now},
+		"$unset":       bson.M{"share_token": ""},
+		"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "items": bson.A{}, "created_at": now},
+	}
+
+	if *sharing.Public {
+		token, err := newShareToken()
+
+		if err != nil {
+			log.Println("wishlist sharing failed:", err)
+			respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not share the wishlist"})
+			return
+		}
+
+		// a wishlist shared already keeps its token
+		filter["public"] = bson.M{"$ne": true}
+		update = bson.M{
+			"$set":         bson.M{"public": true, "share_token": token, "updated_at": now},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID(), "items": bson.A{}, "created_at": now},
+		}
+	}
+
+	_, err := wishlistsCollection.UpdateOne(c, filter, update, options.Update().SetUpsert(true))
+
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		log.Println("wishlist sharing failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not share the wishlist"})
+		return
+	}
+
+	respondWishlist(c, user)
+}
+
+// GetSharedWishlist godoc
+// @Summary      Get a shared wishlist
+// @Description  get a public wishlist by its share token
+// @Tags         wishlist
+// @Accept       json
+// @Produce      json,xml,application/x-yaml,application/x-msgpack
+// @Param        token  path      string  true  "Share token"
+// @Success      200  {object}  models.WishlistView
+// @Failure      404  {object}  models.ErrorMessage
+// @Failure      406  {object}  models.ErrorMessage
+// @Failure      500  {object}  models.ErrorMessage
+// @Router       /v1/wishlists/{token} [get]
+func GetSharedWishlist(c *gin.Context) {
+	if !negotiate(c) {
+		return
+	}
+
+	var wishlist models.Wishlist
+	var view models.WishlistView
+
+	err := wishlistsCollection.FindOne(c, bson.M{"share_token": c.Param("token"), "public": true}).Decode(&wishlist)
+
+	if err == mongo.ErrNoDocuments {
+		respond(c, http.StatusNotFound, models.ErrorMessage{Error: "wishlist not found"})
+		return
+	}
+
+	if err == nil {
+		view, err = wishlistView(c, wishlist)
+	}
+
+	if err != nil {
+		log.Println("wishlist failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the wishlist"})
+		return
+	}
+
+	respond(c, http.StatusOK, view)
+}
+
+func bindWishlistRequest(c *gin.Context, req interface{}) (string, bool) {
+	if !negotiate(c) {
+		return "", false
+	}
+
+	bodyFormat, ok := bodyBinding(c)
+
+	if !ok {
+		respond(c, http.StatusUnsupportedMediaType, models.ErrorMessage{Error: "unsupported media type"})
+		return "", false
+	}
+
+	user, ok := requireUser(c)
+
+	if !ok {
+		return "", false
+	}
+
+	if err := c.ShouldBindWith(req, bodyFormat); err != nil {
+		respond(c, http.StatusUnprocessableEntity, gin.H{"message": "invalid data"})
+		return "", false
+	}
+
+	if validationErr := validate.Struct(req); validationErr != nil {
+		respond(c, http.StatusUnprocessableEntity, models.ErrorMessage{Error: validationErr.Error()})
+		return "", false
+	}
+
+	return user, true
+}
+
+// respondWishlist answers with the wishlist of the user, an empty one when
+// there is none
+func respondWishlist(c *gin.Context, user string) {
+	wishlist := models.Wishlist{Items: []models.WishlistItem{}}
+
+	var view models.WishlistView
+
+	err := wishlistsCollection.FindOne(c, bson.M{"user_id": user}).Decode(&wishlist)
+
+	if err == nil || err == mongo.ErrNoDocuments {
+		view, err = wishlistView(c, wishlist)
+	}
+
+	if err != nil {
+		log.Println("wishlist failed:", err)
+		respond(c, http.StatusInternalServerError, models.ErrorMessage{Error: "could not load the wishlist"})
+		return
+	}
+
+	respond(c, http.StatusOK, view)
+}
+
+// wishlistView shows the wishlist with its albums as they are now. The albums
+// deleted meanwhile are skipped.
+func wishlistView(ctx context.Context, wishlist models.Wishlist) (models.WishlistView, error) {
+	view := models.WishlistView{
+		Items:      []models.WishlistLine{},
+		Public:     wishlist.Public,
+		ShareToken: wishlist.ShareToken,
+		Updated_at: wishlist.Updated_at,
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(wishlist.Items))
+	for _, item := range wishlist.Items {
+		ids = append(ids, item.AlbumID)
+	}
+
+	albums, err := findAlbums(ctx, ids)
+
+	if err != nil {
+		return view, err
+	}
+
+	for _, item := range wishlist.Items {
+		album, found := albums[item.AlbumID]
+
+		if !found {
+			continue
+		}
+
+		currency := album.Currency
+		if currency == "" {
+			currency = defaultCurrency
+		}
+
+		price, _ := convertPrice(album.Price, currency, currency)
+		added, _ := convertPrice(item.Price, item.Currency, item.Currency)
+
+		view.Items = append(view.Items, models.WishlistLine{
+			AlbumID:      album.ID,
+			Title:        album.Title,
+			Artist:       album.Artist,
+			Price:        price,
+			AddedPrice:   added,
+			PriceDropped: currency == item.Currency && album.Price.Cmp(item.Price) < 0,
+			Added_at:     item.Added_at,
+		})
+	}
+
+	return view, nil
+}
+
+// newShareToken is a random token naming a shared wishlist
+func newShareToken() (string, error) {
+	token := make([]byte, 16)
+
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,150 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"rest/database"
+	"rest/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWishlistRoutes(t *testing.T) {
+
+	customer := asUser(t, "wishlist-test-user")
+
+	request("DELETE", "/wishlist", "", customer)
+
+	var kept, deleted PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Wished album", "artist": "Me Owais", "price": 20, "currency": "USD"}`, admin).Body.Bytes(), &kept)
+	json.Unmarshal(request("POST", "/albums", `{"title": "Gone album", "artist": "Me Owais", "price": 20, "currency": "USD"}`, admin).Body.Bytes(), &deleted)
+
+	item := func(id string) string {
+		return `{"album_id": "` + id + `"}`
+	}
+
+	test_cases := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		headers  map[string]string
+		response string
+		status   int
+	}{
+		{
+			name:     "try to get a wishlist anonymously",
+			method:   "GET",
+			path:     "/wishlist",
+			response: `{"error":"X-User-ID header required"}`,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "try to save a missing album",
+			method:   "POST",
+			path:     "/wishlist/items",
+			body:     item("000000000000000000000000"),
+			headers:  customer,
+			response: `{"error":"album not found"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "try to remove an album not in the wishlist",
+			method:   "DELETE",
+			path:     "/wishlist/items/000000000000000000000000",
+			headers:  customer,
+			response: `{"error":"album not in the wishlist"}`,
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "try to read a wishlist that isn't shared",
+			method:   "GET",
+			path:     "/wishlists/nope",
+			response: `{"error":"wishlist not found"}`,
+			status:   http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := request(tc.method, tc.path, tc.body, tc.headers)
+
+			assert.Equal(t, tc.status, w.Code)
+
+			if tc.response != "" {
+				assert.Equal(t, tc.response, w.Body.String())
+			}
+		})
+	}
+
+	request("POST", "/wishlist/items", item(kept.InsertedID), customer)
+	request("POST", "/wishlist/items", item(kept.InsertedID), customer)
+	request("POST", "/wishlist/items", item(deleted.InsertedID), customer)
+
+	var wishlist models.WishlistView
+	json.Unmarshal(request("GET", "/wishlist", "", customer).Body.Bytes(), &wishlist)
+
+	assert.Len(t, wishlist.Items, 2)
+	assert.False(t, wishlist.Public)
+
+	// deleted albums leave the wishlists
+	assert.Equal(t, http.StatusOK, request("DELETE", "/albums/"+deleted.InsertedID, "", admin).Code)
+
+	// a price drop notifies the users who saved the album
+	assert.Equal(t, http.StatusOK, request("PATCH", "/albums/"+kept.InsertedID, `{"price": 15}`, admin).Code)
+
+	json.Unmarshal(request("PUT", "/wishlist/sharing", `{"public": true}`, customer).Body.Bytes(), &wishlist)
+
+	if assert.Len(t, wishlist.Items, 1) {
+		assert.Equal(t, "15.00", wishlist.Items[0].Price.Amount.String())
+		assert.Equal(t, "20.00", wishlist.Items[0].AddedPrice.Amount.String())
+		assert.True(t, wishlist.Items[0].PriceDropped)
+	}
+
+	assert.True(t, wishlist.Public)
+	assert.NotEmpty(t, wishlist.ShareToken)
+
+	token := wishlist.ShareToken
+	assert.Equal(t, http.StatusOK, request("GET", "/wishlists/"+token, "", nil).Code)
+
+	request("PUT", "/wishlist/sharing", `{"public": false}`, customer)
+	assert.Equal(t, http.StatusNotFound, request("GET", "/wishlists/"+token, "", nil).Code)
+
+	var notifications []models.Notification
+	json.Unmarshal(request("GET", "/notifications?unread=true", "", customer).Body.Bytes(), &notifications)
+
+	if assert.NotEmpty(t, notifications) {
+		assert.Equal(t, models.NotificationPriceDrop, notifications[0].Type)
+		assert.Equal(t, http.StatusOK, request("POST", "/notifications/"+notifications[0].ID.Hex()+"/read", "", customer).Code)
+	}
+
+	assert.Equal(t, http.StatusOK, request("DELETE", "/wishlist", "", customer).Code)
+	assert.Equal(t, http.StatusOK, request("DELETE", "/albums/"+kept.InsertedID, "", admin).Code)
+}
+
+func TestBatchDeleteLeavesWishlists(t *testing.T) {
+
+	customer := asUser(t, "wishlist-test-bulk")
+
+	var postRes PostResponse
+	json.Unmarshal(request("POST", "/albums", `{"title": "Bulk wished album", "artist": "Me Owais", "price": 20, "currency": "USD"}`, admin).Body.Bytes(), &postRes)
+
+	assert.Equal(t, http.StatusOK, request("POST", "/wishlist/items", `{"album_id": "`+postRes.InsertedID+`"}`, customer).Code)
+
+	w := request("POST", "/albums:batchDelete", `{"ids": ["`+postRes.InsertedID+`"]}`, admin)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the wishlist view skips missing albums, so look at the stored items
+	id, _ := primitive.ObjectIDFromHex(postRes.InsertedID)
+	wishlists := database.OpenCollection(database.DBinstance(), "wishlists")
+
+	n, err := wishlists.CountDocuments(context.Background(), bson.M{"items.album_id": id})
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	assert.Equal(t, http.StatusOK, request("DELETE", "/wishlist", "", customer).Code)
+}
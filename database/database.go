@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -39,3 +40,14 @@ func OpenCollection(client *mongo.Client, collectionName string) *mongo.Collecti
 
 	return collection
 }
+
+//OpenBucket opens a GridFS bucket for files stored next to the collections
+func OpenBucket(client *mongo.Client, bucketName string) *gridfs.Bucket {
+
+	bucket, err := gridfs.NewBucket(client.Database("cluster0"), options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return bucket
+}
@@ -0,0 +1,396 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//SuggestCollation compares strings ignoring case and accents, queries have
+//to use it as well to be served by the matching indexes
+var SuggestCollation = &options.Collation{Locale: "en", Strength: 1}
+
+//CreateAlbumIndexes makes sure the indexes the album queries rely on exist
+func CreateAlbumIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// full-text search, title matches rank above artist matches
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "artist", Value: "text"}},
+			Options: options.Index().
+				SetName("album_text").
+				SetWeights(bson.M{"title": 3, "artist": 2}),
+		},
+		{
+			// case and accent insensitive prefix lookups for suggestions
+			Keys:    bson.D{{Key: "artist", Value: 1}},
+			Options: options.Index().SetName("artist_ci").SetCollation(SuggestCollation),
+		},
+		{
+			Keys:    bson.D{{Key: "title", Value: 1}},
+			Options: options.Index().SetName("title_ci").SetCollation(SuggestCollation),
+		},
+		{
+			// candidates of the fuzzy search
+			Keys: bson.D{{Key: "title_grams", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "artist_grams", Value: 1}},
+		},
+		{
+			// the importer matches albums on the exact (title, artist) pair,
+			// which the collated indexes above can't serve. It isn't unique,
+			// albums may share the pair, so imports run one at a time, see
+			// controller.LockImport
+			Keys: bson.D{{Key: "title", Value: 1}, {Key: "artist", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "artist_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "genres", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "tags", Value: 1}},
+		},
+		{
+			// SKUs are unique across the catalog, albums without variants are left out
+			Keys: bson.D{{Key: "variants.sku", Value: 1}},
+			Options: options.Index().
+				SetName("variant_sku_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "label_id", Value: 1}, {Key: "release_date", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "release_date", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "catalog_number", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "barcode", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "format", Value: 1}, {Key: "country", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "inventory.low_stock", Value: 1}},
+		},
+		{
+			// ?sort=rating
+			Keys: bson.D{{Key: "rating.average", Value: -1}, {Key: "rating.count", Value: -1}, {Key: "_id", Value: 1}},
+		},
+		{
+			// the latest change of the catalog, for Last-Modified
+			Keys: bson.D{{Key: "updated_at", Value: -1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//NameCollation makes artist, genre and label names unique regardless of case
+var NameCollation = &options.Collation{Locale: "en", Strength: 2}
+
+//CreateArtistIndexes makes sure the indexes of the artists collection exist
+func CreateArtistIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().
+			SetName("name_unique").
+			SetUnique(true).
+			SetCollation(NameCollation),
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateLabelIndexes makes label names unique regardless of case
+func CreateLabelIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().
+			SetName("name_unique").
+			SetUnique(true).
+			SetCollation(NameCollation),
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateGenreIndexes makes genre names unique among their siblings, regardless of case
+func CreateGenreIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().
+				SetName("parent_name_unique").
+				SetUnique(true).
+				SetCollation(NameCollation),
+		},
+		{
+			Keys: bson.D{{Key: "ancestors", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreatePreviewIndexes indexes the preview files by the album and track they belong to
+func CreatePreviewIndexes(bucket *gridfs.Bucket) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := bucket.GetFilesCollection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "metadata.track_id", Value: 1}, {Key: "uploadDate", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "metadata.album_id", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreatePriceIndexes indexes the price history by album and the scheduled
+//prices by what the scheduler looks for
+func CreatePriceIndexes(prices *mongo.Collection, schedules *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := prices.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "album_id", Value: 1}, {Key: "changed_at", Value: -1}},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = schedules.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "album_id", Value: 1}, {Key: "starts_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "starts_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "ends_at", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateInventoryIndexes indexes the reservations by what the expiry looks for
+//and the inventory ledger by album
+func CreateInventoryIndexes(reservations *mongo.Collection, movements *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := reservations.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = movements.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "album_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateCartIndexes gives every user and every anonymous session a single cart,
+//and lets the database remove the carts once they expire
+func CreateCartIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().
+				SetName("user_id_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"user_id": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "session_id", Value: 1}},
+			Options: options.Index().
+				SetName("session_id_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"session_id": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateOrderIndexes indexes the orders by user, newest first, and by what the
+//expiry of unpaid orders looks for
+func CreateOrderIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreatePromotionIndexes makes promotion codes unique and indexes the
+//redemptions by promotion and user, for the per user limits
+func CreatePromotionIndexes(promotions *mongo.Collection, redemptions *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := promotions.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().
+			SetName("code_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"code": bson.M{"$type": "string"}}),
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = redemptions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "promotion_id", Value: 1}, {Key: "user_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "order_id", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateReviewIndexes allows one review per user and album and lists the
+//reviews of an album newest first
+func CreateReviewIndexes(collection *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "album_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("album_user_unique").SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "album_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+//CreateWishlistIndexes allows one wishlist per user, finds the shared ones by
+//token and the ones holding an album, and removes notifications after 90 days
+func CreateWishlistIndexes(wishlists *mongo.Collection, notifications *mongo.Collection) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := wishlists.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("user_unique").SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "share_token", Value: 1}},
+			Options: options.Index().
+				SetName("share_token_unique").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"share_token": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "items.album_id", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = notifications.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(90 * 24 * 60 * 60),
+		},
+	})
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
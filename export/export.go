@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"rest/models"
+	"strconv"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	XLSX   Format = "xlsx"
+)
+
+// column headers shared by the tabular formats
+var header = []string{"_id", "title", "artist", "price", "currency", "created_at", "updated_at"}
+
+// Writer writes albums one at a time so exports never hold the whole catalog in memory
+type Writer interface {
+	Write(album models.Album) error
+	// Close flushes the remaining output, it does not close the underlying writer
+	Close() error
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case CSV, NDJSON, XLSX:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case NDJSON:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+func NewWriter(f Format, w io.Writer) (Writer, error) {
+	switch f {
+	case CSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(header)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return &ndjsonWriter{enc: enc}, nil
+	case XLSX:
+		return newXLSXWriter(w)
+	}
+
+	return nil, fmt.Errorf("unsupported format %q", f)
+}
+
+func row(album models.Album) []string {
+	return []string{
+		album.ID.Hex(),
+		album.Title,
+		album.Artist,
+		strconv.FormatFloat(float64(album.Price), 'f', -1, 64),
+		album.Currency,
+		album.Created_at.Format(time.RFC3339),
+		album.Updated_at.Format(time.RFC3339),
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(album models.Album) error {
+	return c.w.Write(row(album))
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(album models.Album) error {
+	return n.enc.Encode(album)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
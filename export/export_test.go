@@ -0,0 +1,87 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"rest/export"
+	"rest/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var album = models.Album{
+	ID:         primitive.NewObjectIDFromTimestamp(time.Date(2022, 4, 8, 0, 0, 0, 0, time.UTC)),
+	Title:      "Blue <Train>",
+	Artist:     "John Coltrane",
+	Price:      56.99,
+	Currency:   "USD",
+	Created_at: time.Date(2022, 4, 8, 10, 0, 0, 0, time.UTC),
+	Updated_at: time.Date(2022, 4, 9, 10, 0, 0, 0, time.UTC),
+}
+
+func TestWriter(t *testing.T) {
+
+	test_cases := []struct {
+		name     string
+		format   export.Format
+		contains []string
+	}{
+		{
+			name:   "export csv",
+			format: export.CSV,
+			contains: []string{
+				"_id,title,artist,price,currency,created_at,updated_at\n",
+				album.ID.Hex() + ",Blue <Train>,John Coltrane,56.99,USD,2022-04-08T10:00:00Z,2022-04-09T10:00:00Z\n",
+			},
+		},
+		{
+			name:     "export ndjson",
+			format:   export.NDJSON,
+			contains: []string{`"title":"Blue <Train>"`, "}\n"},
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w, err := export.NewWriter(tc.format, &buf)
+			assert.NoError(t, err)
+			assert.NoError(t, w.Write(album))
+			assert.NoError(t, w.Close())
+
+			for _, s := range tc.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+		})
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := export.NewWriter(export.XLSX, &buf)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(album))
+	assert.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			b, _ := io.ReadAll(r)
+			sheet = string(b)
+		}
+	}
+
+	assert.Contains(t, sheet, `<c t="inlineStr"><is><t>Blue &lt;Train&gt;</t></is></c>`)
+	assert.Contains(t, sheet, `<c><v>56.99</v></c>`)
+	assert.Contains(t, sheet, `</sheetData></worksheet>`)
+}
@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// images with more pixels are refused before decoding, a small file can
+// still describe a huge image
+const maxPixels = 50_000_000
+
+// Original names the uploaded image itself among the sizes
+const Original = "original"
+
+type Size struct {
+	Name string
+	// the longest side of the thumbnail in pixels
+	Max int
+}
+
+// Sizes are the thumbnails made of every cover
+var Sizes = []Size{
+	{Name: "small", Max: 100},
+	{Name: "medium", Max: 300},
+	{Name: "large", Max: 600},
+}
+
+// ContentTypes maps the accepted upload types to their image format
+var ContentTypes = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+var ErrTooLarge = errors.New("image dimensions are too large")
+
+// Decode reads a JPEG, PNG or WebP image and returns it with its format
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return img, format, nil
+}
+
+// Thumbnail scales img down so its longest side is at most max pixels,
+// keeping the aspect ratio. Smaller images are returned as they are.
+func Thumbnail(img image.Image, max int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= max && height <= max {
+		return img
+	}
+
+	if width >= height {
+		width, height = max, height*max/width
+	} else {
+		width, height = width*max/height, max
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumb, thumb.Bounds(), img, bounds, draw.Over, nil)
+
+	return thumb
+}
+
+// Encode writes a thumbnail and returns its content type. PNG sources stay
+// PNG to keep their transparency, the others become JPEG since there is no
+// WebP encoder.
+func Encode(w io.Writer, img image.Image, sourceFormat string) (string, error) {
+	switch sourceFormat {
+	case "png":
+		return "image/png", png.Encode(w, img)
+	case "jpeg", "webp":
+		return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+
+	return "", fmt.Errorf("unsupported image format %q", sourceFormat)
+}
@@ -0,0 +1,76 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"rest/imaging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnail(t *testing.T) {
+
+	test_cases := []struct {
+		name          string
+		width, height int
+		max           int
+		thumbWidth    int
+		thumbHeight   int
+	}{
+		{name: "landscape", width: 1200, height: 600, max: 300, thumbWidth: 300, thumbHeight: 150},
+		{name: "portrait", width: 600, height: 1200, max: 300, thumbWidth: 150, thumbHeight: 300},
+		{name: "square", width: 1000, height: 1000, max: 100, thumbWidth: 100, thumbHeight: 100},
+		{name: "smaller than the thumbnail", width: 80, height: 60, max: 100, thumbWidth: 80, thumbHeight: 60},
+		{name: "very thin", width: 3000, height: 2, max: 100, thumbWidth: 100, thumbHeight: 1},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, tc.width, tc.height))
+			thumb := imaging.Thumbnail(img, tc.max)
+
+			assert.Equal(t, tc.thumbWidth, thumb.Bounds().Dx())
+			assert.Equal(t, tc.thumbHeight, thumb.Bounds().Dy())
+		})
+	}
+}
+
+func TestDecodeAndEncode(t *testing.T) {
+
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	img, format, err := imaging.Decode(buf.Bytes())
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "png", format)
+		assert.Equal(t, 40, img.Bounds().Dx())
+	}
+
+	_, _, err = imaging.Decode([]byte("not an image"))
+	assert.Error(t, err)
+
+	test_cases := []struct {
+		format      string
+		contentType string
+	}{
+		{format: "png", contentType: "image/png"},
+		{format: "jpeg", contentType: "image/jpeg"},
+		{format: "webp", contentType: "image/jpeg"},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.format, func(t *testing.T) {
+			var out bytes.Buffer
+
+			contentType, err := imaging.Encode(&out, img, tc.format)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.contentType, contentType)
+			assert.Equal(t, tc.contentType, http.DetectContentType(out.Bytes()))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"rest/controller"
+	"rest/importer"
+	"rest/models"
+)
+
+// importCommand implements `go run . import [flags] <file>`
+func importCommand(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "csv or ndjson, guessed from the file name when empty")
+	columns := flags.String("map", "", "column mapping, e.g. title=Album,artist=Band,price=Cost")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: rest import [flags] <file>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = path
+	}
+
+	opts := importer.Options{DryRun: *dryRun}
+
+	var err error
+	if opts.Format, err = importer.ParseFormat(*format); err != nil {
+		log.Fatal(err)
+	}
+	if opts.Mapping, err = importer.ParseMapping(*columns); err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+
+	if !opts.DryRun {
+		unlock, err := controller.LockImport(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer unlock()
+	}
+
+	report, err := controller.NewAlbumImporter(func(p models.ImportProgress) {
+		if size > 0 {
+			fmt.Fprintf(os.Stderr, "\r%d rows (%d%%)", p.Rows, p.Bytes*100/size)
+		} else {
+			fmt.Fprintf(os.Stderr, "\r%d rows", p.Rows)
+		}
+	}).Run(context.Background(), file, opts)
+	fmt.Fprintln(os.Stderr)
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	if report.Invalid > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"rest/models"
+	"rest/search"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// at most this many row errors are kept in the report
+const maxReportedErrors = 1000
+
+type Options struct {
+	Format  Format
+	Mapping Mapping
+	// validate every row without writing anything
+	DryRun bool
+}
+
+// Importer upserts albums read from a file, matching existing albums on
+// their (title, artist) pair.
+type Importer struct {
+	Collection *mongo.Collection
+	Validate   *validator.Validate
+	// number of rows written per bulk write
+	BatchSize int
+	// Progress, when set, is called after every batch of rows
+	Progress func(models.ImportProgress)
+	// Currency is the currency of the rows that don't name one
+	Currency string
+	// Artist, when set, links the artist name of a row to an artist document,
+	// returning its id and canonical name. Dry runs leave the artists alone.
+	Artist func(ctx context.Context, name string) (primitive.ObjectID, string, error)
+	// PriceChanged, when set, is called after every batch for each existing
+	// album the batch gave a new price, with the price and currency it had
+	PriceChanged func(ctx context.Context, album models.Album, previous models.Amount, currency string)
+}
+
+func (im *Importer) Run(ctx context.Context, r io.Reader, opts Options) (*models.ImportReport, error) {
+	batchSize := im.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	counter := &countingReader{r: r}
+	report := &models.ImportReport{DryRun: opts.DryRun, Errors: []models.ImportRowError{}}
+
+	var writes []mongo.WriteModel
+	var rows []models.AddAlbum
+
+	flush := func() error {
+		if len(writes) > 0 && !opts.DryRun {
+			albums, err := im.currentAlbums(ctx, rows)
+			if err != nil {
+				return err
+			}
+
+			result, err := im.Collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+			if err != nil {
+				return err
+			}
+
+			report.Inserted += int(result.UpsertedCount)
+			report.Updated += int(result.MatchedCount)
+
+			im.reportPriceChanges(ctx, rows, albums)
+		}
+		writes = writes[:0]
+		rows = rows[:0]
+
+		if im.Progress != nil {
+			im.Progress(models.ImportProgress{Rows: report.Rows, Bytes: counter.n})
+		}
+
+		return nil
+	}
+
+	err := Read(counter, opts.Format, opts.Mapping, func(row Row) error {
+		report.Rows++
+
+		if row.Album.Currency == "" {
+			row.Album.Currency = im.Currency
+		}
+
+		if row.Err == nil {
+			row.Err = im.Validate.Struct(models.Album{
+				Title:    row.Album.Title,
+				Artist:   row.Album.Artist,
+				Price:    models.Amount(row.Album.Price),
+				Currency: row.Album.Currency,
+			})
+		}
+
+		if row.Err != nil {
+			report.Invalid++
+			if len(report.Errors) < maxReportedErrors {
+				report.Errors = append(report.Errors, models.ImportRowError{Line: row.Line, Error: row.Err.Error()})
+			}
+			return nil
+		}
+
+		var artistID primitive.ObjectID
+
+		if im.Artist != nil && !opts.DryRun {
+			var err error
+			if artistID, row.Album.Artist, err = im.Artist(ctx, row.Album.Artist); err != nil {
+				return err
+			}
+		}
+
+		report.Valid++
+		writes = append(writes, upsertModel(row.Album, artistID))
+		rows = append(rows, row.Album)
+
+		if len(writes) < batchSize {
+			return nil
+		}
+
+		return flush()
+	})
+
+	if err != nil {
+		return report, fmt.Errorf("import stopped after %d rows: %w", report.Rows, err)
+	}
+
+	if err = flush(); err != nil {
+		return report, fmt.Errorf("import stopped after %d rows: %w", report.Rows, err)
+	}
+
+	return report, nil
+}
+
+// upsertModel writes the price and currency of the row, except on albums
+// with variants, whose price is the one of their cheapest variant
+func upsertModel(album models.AddAlbum, artistID primitive.ObjectID) mongo.WriteModel {
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	hasVariants := bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$variants", bson.A{}}}}, 0}}
+
+	set := bson.M{
+		"price":      bson.M{"$cond": bson.A{hasVariants, "$price", models.Amount(album.Price)}},
+		"currency":   bson.M{"$cond": bson.A{hasVariants, "$currency", bson.M{"$literal": album.Currency}}},
+		"updated_at": now,
+		"created_at": bson.M{"$ifNull": bson.A{"$created_at", now}},
+		// the grams of the fuzzy search, see search.Grams
+		"title_grams":  bson.M{"$literal": search.Grams(album.Title)},
+		"artist_grams": bson.M{"$literal": search.Grams(album.Artist)},
+	}
+	if !artistID.IsZero() {
+		set["artist_id"] = artistID
+	}
+
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"title": album.Title, "artist": album.Artist}).
+		SetUpdate(mongo.Pipeline{{{Key: "$set", Value: set}}}).
+		SetUpsert(true)
+}
+
+// albumKey is the (title, artist) pair the import matches albums on
+type albumKey struct {
+	title, artist string
+}
+
+// currentAlbums loads the albums the rows match, before the rows are written,
+// when their price changes are reported
+func (im *Importer) currentAlbums(ctx context.Context, rows []models.AddAlbum) (map[albumKey]models.Album, error) {
+	albums := map[albumKey]models.Album{}
+
+	if im.PriceChanged == nil {
+		return albums, nil
+	}
+
+	pairs := make(bson.A, 0, len(rows))
+	for _, row := range rows {
+		pairs = append(pairs, bson.M{"title": row.Title, "artist": row.Artist})
+	}
+
+	cursor, err := im.Collection.Find(ctx, bson.M{"$or": pairs}, options.Find().
+		SetProjection(bson.M{"title": 1, "artist": 1, "price": 1, "currency": 1, "variants": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []models.Album
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	for _, album := range found {
+		albums[albumKey{album.Title, album.Artist}] = album
+	}
+
+	return albums, nil
+}
+
+// reportPriceChanges calls PriceChanged for the albums whose price the rows
+// changed. New albums have no price to change from, and albums with variants
+// keep theirs.
+func (im *Importer) reportPriceChanges(ctx context.Context, rows []models.AddAlbum, albums map[albumKey]models.Album) {
+	if im.PriceChanged == nil {
+		return
+	}
+
+	now, _ := time.Parse(time.RFC3339, time.Now().Format(time.RFC3339))
+
+	for _, row := range rows {
+		key := albumKey{row.Title, row.Artist}
+		album, found := albums[key]
+
+		if !found || len(album.Variants) > 0 {
+			continue
+		}
+
+		previous, currency := album.Price, album.Currency
+		if currency == "" {
+			currency = im.Currency
+		}
+
+		album.Price, album.Currency, album.Updated_at = models.Amount(row.Price), row.Currency, now
+		// a later row for the same album changes it from this price
+		albums[key] = album
+
+		if album.Price.Cmp(previous) != 0 || album.Currency != currency {
+			im.PriceChanged(ctx, album, previous, currency)
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
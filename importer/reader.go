@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"rest/models"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// album fields that can be mapped to a column
+var fields = []string{"title", "artist", "price", "currency"}
+
+// fields a CSV file may leave out
+var optional = map[string]bool{"currency": true}
+
+// ParseFormat accepts a format name or a file name with a known extension
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(s)
+	if ext := filepath.Ext(s); ext != "" {
+		s = ext[1:]
+	}
+
+	switch s {
+	case "csv":
+		return CSV, nil
+	case "ndjson", "jsonl":
+		return NDJSON, nil
+	}
+
+	return "", fmt.Errorf("unsupported format %q", s)
+}
+
+// Mapping maps an album field to the CSV column or NDJSON key holding it.
+// Unmapped fields are read from the column named like the field.
+type Mapping map[string]string
+
+// ParseMapping parses mappings written as "title=Album Name,artist=Band"
+func ParseMapping(s string) (Mapping, error) {
+	m := Mapping{}
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		field := strings.ToLower(strings.TrimSpace(parts[0]))
+
+		if len(parts) != 2 || !isField(field) || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid column mapping %q", pair)
+		}
+
+		m[field] = strings.TrimSpace(parts[1])
+	}
+
+	return m, nil
+}
+
+func (m Mapping) column(field string) string {
+	if column, ok := m[field]; ok {
+		return column
+	}
+
+	return field
+}
+
+func isField(s string) bool {
+	for _, field := range fields {
+		if field == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Row is a single record read from the file. Err is set when the record
+// could not be turned into an album, Line points at where it starts.
+type Row struct {
+	Line  int
+	Album models.AddAlbum
+	Err   error
+}
+
+// Read streams the records of r to fn, stopping at the first error fn returns
+func Read(r io.Reader, format Format, m Mapping, fn func(Row) error) error {
+	switch format {
+	case CSV:
+		return readCSV(r, m, fn)
+	case NDJSON:
+		return readNDJSON(r, m, fn)
+	}
+
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+func readCSV(r io.Reader, m Mapping, fn func(Row) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("line 1: %w", err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	columns := map[string]int{}
+	for _, field := range fields {
+		i, ok := index[m.column(field)]
+		if !ok && optional[field] {
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("line 1: missing column %q", m.column(field))
+		}
+		columns[field] = i
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			// a broken quote makes the rest of the file unreadable
+			return err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := Row{Line: line}
+
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row.Album.Title = value("title")
+		row.Album.Artist = value("artist")
+		row.Album.Currency = strings.ToUpper(value("currency"))
+
+		if price := value("price"); price != "" {
+			row.Album.Price, err = strconv.ParseFloat(price, 64)
+			if err != nil {
+				row.Err = fmt.Errorf("%s: invalid number %q", m.column("price"), price)
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+func readNDJSON(r io.Reader, m Mapping, fn func(Row) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := Row{Line: line}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			row.Err = errors.New("invalid json")
+		} else {
+			row.Album, row.Err = albumFromRecord(record, m)
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func albumFromRecord(record map[string]interface{}, m Mapping) (models.AddAlbum, error) {
+	var album models.AddAlbum
+
+	for _, field := range []string{"title", "artist", "currency"} {
+		value, ok := record[m.column(field)]
+		if !ok || value == nil {
+			continue
+		}
+
+		s, ok := value.(string)
+		if !ok {
+			return album, fmt.Errorf("%s: expected a string", m.column(field))
+		}
+
+		switch field {
+		case "title":
+			album.Title = strings.TrimSpace(s)
+		case "artist":
+			album.Artist = strings.TrimSpace(s)
+		case "currency":
+			album.Currency = strings.ToUpper(strings.TrimSpace(s))
+		}
+	}
+
+	switch price := record[m.column("price")].(type) {
+	case nil:
+	case float64:
+		album.Price = price
+	case string:
+		var err error
+		if album.Price, err = strconv.ParseFloat(strings.TrimSpace(price), 64); err != nil {
+			return album, fmt.Errorf("%s: invalid number %q", m.column("price"), price)
+		}
+	default:
+		return album, fmt.Errorf("%s: expected a number", m.column("price"))
+	}
+
+	return album, nil
+}
@@ -0,0 +1,101 @@
+package importer_test
+
+import (
+	"rest/importer"
+	"rest/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+
+	test_cases := []struct {
+		name    string
+		format  importer.Format
+		mapping string
+		body    string
+		rows    []importer.Row
+		errors  []int
+		err     bool
+	}{
+		{
+			name:   "read csv rows",
+			format: importer.CSV,
+			body:   "title,artist,price\nBlue Train,John Coltrane,56.99\n\"Jeru\",Gerry Mulligan,17.99\n",
+			rows: []importer.Row{
+				{Line: 2, Album: models.AddAlbum{Title: "Blue Train", Artist: "John Coltrane", Price: 56.99}},
+				{Line: 3, Album: models.AddAlbum{Title: "Jeru", Artist: "Gerry Mulligan", Price: 17.99}},
+			},
+		},
+		{
+			name:    "read csv rows with a column mapping",
+			format:  importer.CSV,
+			mapping: "title=Album,artist=Band,price=Cost",
+			body:    "Band,Album,Cost,Year\nJohn Coltrane,Blue Train,56.99,1957\n",
+			rows: []importer.Row{
+				{Line: 2, Album: models.AddAlbum{Title: "Blue Train", Artist: "John Coltrane", Price: 56.99}},
+			},
+		},
+		{
+			name:   "read csv rows with a currency",
+			format: importer.CSV,
+			body:   "title,artist,price,currency\nBlue Train,John Coltrane,56.99,eur\n",
+			rows: []importer.Row{
+				{Line: 2, Album: models.AddAlbum{Title: "Blue Train", Artist: "John Coltrane", Price: 56.99, Currency: "EUR"}},
+			},
+		},
+		{
+			name:   "report csv rows with a bad price",
+			format: importer.CSV,
+			body:   "title,artist,price\nBlue Train,John Coltrane,cheap\n",
+			errors: []int{2},
+		},
+		{
+			name:   "fail on a missing csv column",
+			format: importer.CSV,
+			body:   "title,artist\nBlue Train,John Coltrane\n",
+			err:    true,
+		},
+		{
+			name:    "read ndjson rows",
+			format:  importer.NDJSON,
+			mapping: "artist=band",
+			body:    "{\"title\": \"Blue Train\", \"band\": \"John Coltrane\", \"price\": 56.99}\n\n{\"title\": \"Jeru\", \"band\": \"Gerry Mulligan\", \"price\": \"17.99\"}\n",
+			rows: []importer.Row{
+				{Line: 1, Album: models.AddAlbum{Title: "Blue Train", Artist: "John Coltrane", Price: 56.99}},
+				{Line: 3, Album: models.AddAlbum{Title: "Jeru", Artist: "Gerry Mulligan", Price: 17.99}},
+			},
+		},
+		{
+			name:   "report broken ndjson rows",
+			format: importer.NDJSON,
+			body:   "{\"title\": \"Blue Train\"\n{\"title\": 1}\n",
+			errors: []int{1, 2},
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping, err := importer.ParseMapping(tc.mapping)
+			assert.NoError(t, err)
+
+			var rows []importer.Row
+			var errors []int
+
+			err = importer.Read(strings.NewReader(tc.body), tc.format, mapping, func(row importer.Row) error {
+				if row.Err != nil {
+					errors = append(errors, row.Line)
+				} else {
+					rows = append(rows, row)
+				}
+				return nil
+			})
+
+			assert.Equal(t, tc.err, err != nil)
+			assert.Equal(t, tc.rows, rows)
+			assert.Equal(t, tc.errors, errors)
+		})
+	}
+}
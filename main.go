@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"os"
+	"rest/controller"
 	_ "rest/docs"
 	"rest/middlewares"
 	"rest/routes"
@@ -12,14 +15,27 @@ import (
 // @schemes http https
 
 // @host      localhost:8080
-// @BasePath /api/v1
+// @BasePath /api
 
 // @securityDefinitions.apikey  bearer
 // @in                          header
 // @name                        Authorization
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		importCommand(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrateCommand(os.Args[2:])
+		return
+	}
+
 	r := routes.Routes()
 
+	controller.StartPriceScheduler(context.Background())
+	controller.StartReservationExpiry(context.Background())
+
 	r.Run("localhost:" + middlewares.DotEnvVariable("PORT"))
 }
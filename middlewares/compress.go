@@ -0,0 +1,235 @@
+package middlewares
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+// supported content codings, in the order we prefer them when the client
+// accepts several with the same weight
+var encodings = []string{"br", "zstd", "gzip"}
+
+// content types that are already compressed
+var incompressible = []string{"image/", "audio/", "video/", "application/zip", "application/vnd.openxmlformats"}
+
+// Compress compresses responses of at least minSize bytes with the best
+// encoding the client lists in Accept-Encoding. Responses are buffered up to
+// minSize, a flush before that (a streamed response) compresses right away.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := AcceptedEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize, status: http.StatusOK}
+		c.Writer = w
+
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// AcceptedEncoding picks the supported encoding with the highest weight in an
+// Accept-Encoding header, or "" when the response should not be compressed
+func AcceptedEncoding(header string) string {
+	weights := map[string]float64{}
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		weight := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					weight = q
+				}
+			}
+		}
+
+		if coding == "*" {
+			for _, encoding := range encodings {
+				if _, ok := weights[encoding]; !ok {
+					weights[encoding] = weight
+				}
+			}
+			continue
+		}
+
+		weights[coding] = weight
+	}
+
+	best, bestWeight := "", 0.0
+
+	for _, encoding := range encodings {
+		if weights[encoding] > bestWeight {
+			best, bestWeight = encoding, weights[encoding]
+		}
+	}
+
+	return best
+}
+
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	status   int
+	buf      []byte
+	// started is set once the header went out, enc when the body is compressed
+	started bool
+	enc     io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.started {
+		w.status = code
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.start(false)
+}
+
+func (w *compressWriter) Status() int {
+	if !w.started {
+		return w.status
+	}
+
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Written() bool {
+	return w.started || len(w.buf) > 0
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.minSize {
+			return len(p), nil
+		}
+
+		w.start(true)
+		return len(p), w.flushBuffer()
+	}
+
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if !w.started {
+		w.start(true)
+		w.flushBuffer()
+	}
+
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// start sends the header, switching to the compressed body when it is worth it
+func (w *compressWriter) start(compress bool) {
+	if w.started {
+		return
+	}
+	w.started = true
+
+	header := w.Header()
+
+	if compress && w.compressible(header) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.enc = newEncoder(w.encoding, w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	if w.status < http.StatusOK || w.status == http.StatusNoContent ||
+		w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	for _, prefix := range incompressible {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (w *compressWriter) flushBuffer() error {
+	buf := w.buf
+	w.buf = nil
+
+	if len(buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+
+	return err
+}
+
+// finish writes whatever is still buffered, small responses go out as they are
+func (w *compressWriter) finish() {
+	w.start(false)
+	w.flushBuffer()
+
+	if w.enc != nil {
+		w.enc.Close()
+	}
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	switch encoding {
+	case "br":
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	case "zstd":
+		enc, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		return enc
+	}
+
+	enc, _ := gzip.NewWriterLevel(w, gzip.DefaultCompression)
+	return enc
+}
@@ -0,0 +1,101 @@
+package middlewares_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rest/middlewares"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+
+	test_cases := []struct {
+		header   string
+		encoding string
+	}{
+		{header: "", encoding: ""},
+		{header: "identity", encoding: ""},
+		{header: "gzip, deflate", encoding: "gzip"},
+		{header: "gzip, deflate, br", encoding: "br"},
+		{header: "gzip;q=1.0, br;q=0.5", encoding: "gzip"},
+		{header: "zstd, gzip;q=0.8", encoding: "zstd"},
+		{header: "*;q=0.1, gzip;q=0", encoding: "br"},
+		{header: "br;q=0, gzip;q=0", encoding: ""},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.encoding, middlewares.AcceptedEncoding(tc.header))
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("album ", 1000)
+
+	router := gin.New()
+	router.Use(middlewares.Compress(1024))
+	router.GET("/small", func(c *gin.Context) { c.String(http.StatusOK, "album") })
+	router.GET("/large", func(c *gin.Context) { c.String(http.StatusCreated, large) })
+	router.GET("/image", func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(large)) })
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"": func(r io.Reader) (io.Reader, error) { return r, nil },
+		"gzip": func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		"br": func(r io.Reader) (io.Reader, error) {
+			return brotli.NewReader(r), nil
+		},
+		"zstd": func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		},
+	}
+
+	test_cases := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		encoding       string
+		status         int
+		body           string
+	}{
+		{name: "leave small responses alone", path: "/small", acceptEncoding: "gzip", status: http.StatusOK, body: "album"},
+		{name: "compress with gzip", path: "/large", acceptEncoding: "gzip", encoding: "gzip", status: http.StatusCreated, body: large},
+		{name: "compress with brotli", path: "/large", acceptEncoding: "gzip, br", encoding: "br", status: http.StatusCreated, body: large},
+		{name: "compress with zstd", path: "/large", acceptEncoding: "zstd", encoding: "zstd", status: http.StatusCreated, body: large},
+		{name: "leave uncompressed clients alone", path: "/large", status: http.StatusCreated, body: large},
+		{name: "leave images alone", path: "/image", acceptEncoding: "gzip", status: http.StatusOK, body: large},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tc.path, nil)
+			req.Header.Add("Accept-Encoding", tc.acceptEncoding)
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+			r, err := decoders[tc.encoding](w.Body)
+			assert.NoError(t, err)
+
+			body, err := io.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.body, string(body))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"rest/controller"
+)
+
+// migrateCommand implements `go run . migrate`, linking the albums created
+// before artists existed to artist documents and giving the albums created
+// before the fuzzy search its grams
+func migrateCommand(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: rest migrate")
+		os.Exit(2)
+	}
+
+	report, err := controller.MigrateArtists(context.Background())
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrated, err := controller.MigrateSearchGrams(context.Background())
+
+	fmt.Println("search grams:", migrated, "albums")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/xml"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,16 +9,79 @@ import (
 
 // album represents data about a record album.
 type Album struct {
-	ID         primitive.ObjectID `bson:"_id" json:"_id"`
-	Title      string             `json:"title" validate:"required"`
-	Artist     string             `json:"artist" validate:"required"`
-	Price      float64            `json:"price" validate:"required"`
-	Created_at time.Time          `json:"created_at"`
-	Updated_at time.Time          `json:"updated_at"`
+	ID       primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	Title    string             `json:"title" xml:"title" validate:"required"`
+	Artist   string             `json:"artist" xml:"artist" validate:"required"`
+	ArtistID primitive.ObjectID `bson:"artist_id,omitempty" json:"artist_id" xml:"artist_id" yaml:"artist_id"`
+	// the cheapest variant when the album has variants
+	Price Amount `json:"price" xml:"price" validate:"required"`
+	// ISO 4217, the currency of the variants when the album has variants
+	Currency   string               `json:"currency" xml:"currency" validate:"required,iso4217"`
+	Variants   []Variant            `json:"variants" xml:"variants" yaml:"variants" validate:"unique=SKU,dive"`
+	PriceRange *PriceRange          `bson:"price_range" json:"price_range,omitempty" xml:"price_range,omitempty" yaml:"price_range,omitempty"`
+	Tracks     []Track              `json:"tracks" xml:"tracks" yaml:"tracks" validate:"unique=Number,dive"`
+	Duration   int                  `json:"duration" xml:"duration"` // seconds, the sum of the tracks
+	Genres     []primitive.ObjectID `json:"genres" xml:"genres" yaml:"genres"`
+	Tags       []string             `json:"tags" xml:"tags" yaml:"tags" validate:"dive,required,max=50"`
+	// release metadata, the date is written as 2006-01-02
+	ReleaseDate   string             `bson:"release_date" json:"release_date" xml:"release_date" yaml:"release_date" validate:"omitempty,datetime=2006-01-02"`
+	LabelID       primitive.ObjectID `bson:"label_id,omitempty" json:"label_id" xml:"label_id" yaml:"label_id"`
+	CatalogNumber string             `bson:"catalog_number" json:"catalog_number" xml:"catalog_number" yaml:"catalog_number" validate:"max=50"`
+	Barcode       string             `json:"barcode" xml:"barcode" validate:"omitempty,gtin"` // UPC-A, EAN-13 or EAN-8
+	Format        string             `json:"format" xml:"format" validate:"omitempty,oneof=cd vinyl digital"`
+	Country       string             `json:"country" xml:"country" validate:"omitempty,iso3166_1_alpha2"`
+	// grams, the weight of the format when 0
+	Weight    int        `json:"weight" xml:"weight" validate:"min=0"`
+	Inventory *Inventory `bson:"inventory,omitempty" json:"inventory,omitempty" xml:"inventory,omitempty" yaml:"inventory,omitempty"`
+	Rating    *Rating    `bson:"rating,omitempty" json:"rating,omitempty" xml:"rating,omitempty" yaml:"rating,omitempty"`
+	// letter trigrams of the title and the artist name, for the fuzzy search
+	TitleGrams  []string  `bson:"title_grams,omitempty" json:"-" xml:"-" yaml:"-"`
+	ArtistGrams []string  `bson:"artist_grams,omitempty" json:"-" xml:"-" yaml:"-"`
+	Cover       *Cover    `bson:"cover,omitempty" json:"cover,omitempty" xml:"cover,omitempty" yaml:"cover,omitempty"`
+	Created_at  time.Time `json:"created_at" xml:"created_at"`
+	Updated_at  time.Time `json:"updated_at" xml:"updated_at"`
+	// the artist document, only filled in when asked for with ?expand=artist
+	ArtistDetails *Artist `bson:"-" json:"artist_details,omitempty" xml:"artist_details,omitempty" yaml:"artist_details,omitempty"`
+}
+
+// Albums is a list of albums, XML has no bare lists so it is wrapped in an <Albums> element
+type Albums []Album
+
+func (a Albums) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "Albums"
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, album := range a {
+		if err := e.Encode(album); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
 }
 
 type AddAlbum struct {
-	Title  string  `json:"title"`
-	Artist string  `json:"artist"`
-	Price  float64 `json:"price"`
+	Title  string `json:"title" xml:"title"`
+	Artist string `json:"artist" xml:"artist"`
+	// takes precedence over the artist name, which then follows the artist
+	ArtistID string  `json:"artist_id,omitempty" xml:"artist_id,omitempty"`
+	Price    float64 `json:"price" xml:"price"`
+	// defaults to DEFAULT_CURRENCY
+	Currency string     `json:"currency,omitempty" xml:"currency,omitempty"`
+	Tracks   []AddTrack `json:"tracks,omitempty" xml:"tracks,omitempty"`
+	Genres   []string   `json:"genres,omitempty" xml:"genres,omitempty"`
+	Tags     []string   `json:"tags,omitempty" xml:"tags,omitempty"`
+	// 2006-01-02
+	ReleaseDate   string `json:"release_date,omitempty" xml:"release_date,omitempty"`
+	LabelID       string `json:"label_id,omitempty" xml:"label_id,omitempty"`
+	CatalogNumber string `json:"catalog_number,omitempty" xml:"catalog_number,omitempty"`
+	Barcode       string `json:"barcode,omitempty" xml:"barcode,omitempty"`
+	// one of cd, vinyl or digital
+	Format  string `json:"format,omitempty" xml:"format,omitempty"`
+	Country string `json:"country,omitempty" xml:"country,omitempty"`
+	// shipping weight in grams, defaults to the weight of the format
+	Weight int `json:"weight,omitempty" xml:"weight,omitempty"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// artist represents a performer albums are credited to.
+type Artist struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	Name       string             `json:"name" xml:"name" validate:"required"`
+	Created_at time.Time          `json:"created_at" xml:"created_at"`
+	Updated_at time.Time          `json:"updated_at" xml:"updated_at"`
+}
+
+type AddArtist struct {
+	Name string `json:"name" xml:"name"`
+}
+
+type ArtistMigration struct {
+	// artists the string artists of the albums were linked to
+	Artists int `json:"artists"`
+	Albums  int `json:"albums"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "encoding/json"
+
+// batch item statuses reported back to the caller
+const (
+	BatchStatusCreated  = "created"
+	BatchStatusUpdated  = "updated"
+	BatchStatusDeleted  = "deleted"
+	BatchStatusInvalid  = "invalid"
+	BatchStatusNotFound = "not_found"
+	BatchStatusFailed   = "failed"
+	BatchStatusSkipped  = "skipped"
+)
+
+type BatchCreateAlbums struct {
+	Albums []AddAlbum `json:"albums"`
+	// all-or-nothing: the whole batch runs in a single transaction
+	Atomic bool `json:"atomic"`
+}
+
+type BatchUpdateAlbums struct {
+	// every item must carry the "_id" of the album it updates,
+	// the remaining fields are applied like a regular PATCH
+	Albums []json.RawMessage `json:"albums" swaggertype:"array,object"`
+	Atomic bool              `json:"atomic"`
+}
+
+type BatchDeleteAlbums struct {
+	IDs    []string `json:"ids"`
+	Atomic bool     `json:"atomic"`
+}
+
+type BatchItemResult struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
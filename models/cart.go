@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cart belongs either to a user or to an anonymous session, until it expires
+type Cart struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	UserID     string             `bson:"user_id,omitempty" json:"user_id,omitempty" xml:"user_id,omitempty" yaml:"user_id,omitempty"`
+	SessionID  string             `bson:"session_id,omitempty" json:"session_id,omitempty" xml:"session_id,omitempty" yaml:"session_id,omitempty"`
+	Items      []CartItem         `json:"items" xml:"items" yaml:"items"`
+	Expires_at time.Time          `json:"expires_at" xml:"expires_at"`
+	Created_at time.Time          `json:"created_at" xml:"created_at"`
+	Updated_at time.Time          `json:"updated_at" xml:"updated_at"`
+}
+
+// CartItem is a quantity of an album, with the price it had when it was put
+// in the cart
+type CartItem struct {
+	AlbumID  primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Quantity int                `json:"quantity" xml:"quantity"`
+	Price    Amount             `json:"price" xml:"price"`
+	Currency string             `json:"currency" xml:"currency"`
+	Added_at time.Time          `json:"added_at" xml:"added_at"`
+}
+
+type AddCartItem struct {
+	AlbumID  string `json:"album_id" xml:"album_id" validate:"required"`
+	Quantity int    `json:"quantity" xml:"quantity" validate:"required,min=1,max=99"`
+}
+
+type UpdateCartItem struct {
+	// 0 removes the album from the cart
+	Quantity *int `json:"quantity" xml:"quantity" validate:"required,min=0,max=99"`
+}
+
+// CartView is a cart checked against the current catalog
+type CartView struct {
+	ID         primitive.ObjectID `json:"_id" xml:"_id" yaml:"_id"`
+	UserID     string             `json:"user_id,omitempty" xml:"user_id,omitempty" yaml:"user_id,omitempty"`
+	SessionID  string             `json:"session_id,omitempty" xml:"session_id,omitempty" yaml:"session_id,omitempty"`
+	Items      []CartLine         `json:"items" xml:"items" yaml:"items"`
+	Subtotal   Money              `json:"subtotal" xml:"subtotal" yaml:"subtotal"`
+	Expires_at time.Time          `json:"expires_at" xml:"expires_at" yaml:"expires_at"`
+	Updated_at time.Time          `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+}
+
+// CartLine is an item of a cart at the current price of its album. Lines
+// that aren't Available don't count in the subtotal.
+type CartLine struct {
+	AlbumID  primitive.ObjectID `json:"album_id" xml:"album_id" yaml:"album_id"`
+	Title    string             `json:"title" xml:"title" yaml:"title"`
+	Artist   string             `json:"artist" xml:"artist" yaml:"artist"`
+	Quantity int                `json:"quantity" xml:"quantity" yaml:"quantity"`
+	Price    Money              `json:"price" xml:"price" yaml:"price"`
+	// the price when the album was put in the cart
+	AddedPrice   Money  `json:"added_price" xml:"added_price" yaml:"added_price"`
+	PriceChanged bool   `json:"price_changed" xml:"price_changed" yaml:"price_changed"`
+	Total        Money  `json:"total" xml:"total" yaml:"total"`
+	Available    bool   `json:"available" xml:"available" yaml:"available"`
+	Problem      string `json:"problem,omitempty" xml:"problem,omitempty" yaml:"problem,omitempty"`
+}
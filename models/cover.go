@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cover is the artwork of an album, the images live in GridFS
+type Cover struct {
+	Images     []CoverImage `json:"images" xml:"images" yaml:"images"`
+	Updated_at time.Time    `json:"updated_at" xml:"updated_at"`
+}
+
+type CoverImage struct {
+	// "original" or one of the thumbnail sizes
+	Size        string             `json:"size" xml:"size"`
+	FileID      primitive.ObjectID `bson:"file_id" json:"-" xml:"-" yaml:"-"`
+	ContentType string             `bson:"content_type" json:"content_type" xml:"content_type" yaml:"content_type"`
+	Width       int                `json:"width" xml:"width"`
+	Height      int                `json:"height" xml:"height"`
+	Length      int64              `json:"length" xml:"length"`
+	ETag        string             `json:"etag" xml:"etag"`
+}
@@ -0,0 +1,38 @@
+package models
+
+type FacetCount struct {
+	Value string `json:"value" xml:"value"`
+	Count int    `json:"count" xml:"count"`
+}
+
+type YearCount struct {
+	Year  int `json:"year" xml:"year"`
+	Count int `json:"count" xml:"count"`
+}
+
+// PriceBucket counts the albums priced from Min up to, but not including, Max.
+// The last bucket has no upper bound.
+type PriceBucket struct {
+	Min   float64  `json:"min" xml:"min"`
+	Max   *float64 `json:"max" xml:"max,omitempty"`
+	Count int      `json:"count" xml:"count"`
+}
+
+// Facets holds the counts of the facets asked for, the others stay empty
+type Facets struct {
+	Artist      []FacetCount  `json:"artist,omitempty" xml:"artist,omitempty" yaml:"artist,omitempty"`
+	PriceBucket []PriceBucket `json:"price_bucket,omitempty" xml:"price_bucket,omitempty" yaml:"price_bucket,omitempty"`
+	Year        []YearCount   `json:"year,omitempty" xml:"year,omitempty" yaml:"year,omitempty"`
+}
+
+// FacetedAlbums is the album list response when facets are asked for
+type FacetedAlbums struct {
+	Albums Albums `json:"albums" xml:"Albums"`
+	Facets Facets `json:"facets" xml:"facets"`
+}
+
+// FacetedSearch is the search response when facets are asked for
+type FacetedSearch struct {
+	Hits   []SearchHit `json:"hits" xml:"hit"`
+	Facets Facets      `json:"facets" xml:"facets"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// genre is a node of the genre tree, e.g. Bebop under Jazz.
+type Genre struct {
+	ID       primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	Name     string             `json:"name" xml:"name" validate:"required"`
+	ParentID primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id" xml:"parent_id" yaml:"parent_id"`
+	// the path from the root down to the parent, descendants are found by it
+	Ancestors  []primitive.ObjectID `json:"ancestors" xml:"ancestors" yaml:"ancestors"`
+	Created_at time.Time            `json:"created_at" xml:"created_at"`
+	Updated_at time.Time            `json:"updated_at" xml:"updated_at"`
+}
+
+type AddGenre struct {
+	Name string `json:"name" xml:"name"`
+	// empty for a top level genre
+	ParentID string `json:"parent_id,omitempty" xml:"parent_id,omitempty"`
+}
+
+type TagCount struct {
+	Tag   string `bson:"_id" json:"tag" xml:"tag"`
+	Count int64  `json:"count" xml:"count"`
+}
+
+type RenameTag struct {
+	From string `json:"from" validate:"required"`
+	To   string `json:"to" validate:"required"`
+}
+
+// MergeTags replaces the From tags of every album with To, renaming a tag
+// is merging it alone
+type MergeTags struct {
+	From []string `json:"from" validate:"required,dive,required"`
+	To   string   `json:"to" validate:"required"`
+}
+
+type MergeTagsResult struct {
+	Albums int64 `json:"albums"`
+}
@@ -0,0 +1,22 @@
+package models
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportProgress struct {
+	Rows  int   `json:"rows"`
+	Bytes int64 `json:"bytes"`
+}
+
+type ImportReport struct {
+	DryRun   bool `json:"dry_run"`
+	Rows     int  `json:"rows"`
+	Valid    int  `json:"valid"`
+	Invalid  int  `json:"invalid"`
+	Inserted int  `json:"inserted"`
+	Updated  int  `json:"updated"`
+	// only the first errors are listed, Invalid holds the full count
+	Errors []ImportRowError `json:"errors"`
+}
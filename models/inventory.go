@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Inventory is the stock of an album. Only the inventory endpoints change it,
+// available and low_stock are kept up to date with every change.
+type Inventory struct {
+	OnHand    int `bson:"on_hand" json:"on_hand" xml:"on_hand" yaml:"on_hand"`
+	Reserved  int `json:"reserved" xml:"reserved"`
+	Available int `json:"available" xml:"available"`
+	// low_stock is set once available drops to the threshold, 0 turns it off
+	LowStockThreshold int  `bson:"low_stock_threshold" json:"low_stock_threshold" xml:"low_stock_threshold" yaml:"low_stock_threshold"`
+	LowStock          bool `bson:"low_stock" json:"low_stock" xml:"low_stock" yaml:"low_stock"`
+}
+
+type InventorySettings struct {
+	LowStockThreshold *int `json:"low_stock_threshold" xml:"low_stock_threshold" validate:"required,min=0"`
+}
+
+type AdjustInventory struct {
+	// added to the stock on hand, negative to remove stock
+	Quantity int    `json:"quantity" xml:"quantity" validate:"required"`
+	Reason   string `json:"reason,omitempty" xml:"reason,omitempty" validate:"max=200"`
+}
+
+// reservation statuses
+const (
+	ReservationPending   = "pending"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation holds stock of an album until it is committed, released or
+// expires
+type Reservation struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	AlbumID    primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Quantity   int                `json:"quantity" xml:"quantity"`
+	Status     string             `json:"status" xml:"status"`
+	Expires_at time.Time          `json:"expires_at" xml:"expires_at"`
+	Created_at time.Time          `json:"created_at" xml:"created_at"`
+	Updated_at time.Time          `json:"updated_at" xml:"updated_at"`
+}
+
+type AddReservation struct {
+	Quantity int `json:"quantity" xml:"quantity" validate:"required,min=1"`
+}
+
+// inventory movement types
+const (
+	MovementAdjustment = "adjustment"
+	MovementReserve    = "reserve"
+	MovementCommit     = "commit"
+	MovementRelease    = "release"
+	MovementExpire     = "expire"
+	// the stock of a refunded order that was not shipped
+	MovementRestock = "restock"
+)
+
+// InventoryMovement is an entry of the inventory ledger, every change of the
+// stock of an album leaves one
+type InventoryMovement struct {
+	ID      primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	AlbumID primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Type    string             `json:"type" xml:"type"`
+	// the changes of the stock on hand and of the reserved stock
+	OnHandDelta   int                `bson:"on_hand_delta" json:"on_hand_delta" xml:"on_hand_delta" yaml:"on_hand_delta"`
+	ReservedDelta int                `bson:"reserved_delta" json:"reserved_delta" xml:"reserved_delta" yaml:"reserved_delta"`
+	ReservationID primitive.ObjectID `bson:"reservation_id,omitempty" json:"reservation_id,omitempty" xml:"reservation_id,omitempty" yaml:"reservation_id,omitempty"`
+	Reason        string             `json:"reason,omitempty" xml:"reason,omitempty" yaml:"reason,omitempty"`
+	// the stock after the movement
+	Inventory  Inventory `json:"inventory" xml:"inventory"`
+	Created_at time.Time `json:"created_at" xml:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// label represents a record label albums are released on.
+type Label struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	Name       string             `json:"name" xml:"name" validate:"required"`
+	Country    string             `json:"country" xml:"country" validate:"omitempty,iso3166_1_alpha2"`
+	Created_at time.Time          `json:"created_at" xml:"created_at"`
+	Updated_at time.Time          `json:"updated_at" xml:"updated_at"`
+}
+
+type AddLabel struct {
+	Name string `json:"name" xml:"name"`
+	// ISO 3166-1 alpha-2 code, e.g. GB
+	Country string `json:"country,omitempty" xml:"country,omitempty"`
+}
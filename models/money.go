@@ -0,0 +1,241 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Amount is a price the way the v1 API shows it, a plain number. It is stored
+// as Decimal128 so the database keeps the exact decimal the client sent.
+type Amount float64
+
+func (a Amount) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(strconv.FormatFloat(float64(a), 'f', -1, 64))
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return bson.MarshalValue(d)
+}
+
+// UnmarshalBSONValue also reads the doubles prices were stored as before
+func (a *Amount) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+
+	switch t {
+	case bsontype.Decimal128:
+		f, err := strconv.ParseFloat(raw.Decimal128().String(), 64)
+		*a = Amount(f)
+		return err
+	case bsontype.Double:
+		*a = Amount(raw.Double())
+	case bsontype.Int32:
+		*a = Amount(raw.Int32())
+	case bsontype.Int64:
+		*a = Amount(raw.Int64())
+	case bsontype.Null:
+		*a = 0
+	default:
+		return fmt.Errorf("cannot decode %v into an amount", t)
+	}
+
+	return nil
+}
+
+// Decimal returns the shortest decimal that reads back as the amount
+func (a Amount) Decimal() Decimal {
+	d, _ := ParseDecimal(strconv.FormatFloat(float64(a), 'f', -1, 64))
+	return d
+}
+
+// Cmp compares the exact decimals of a and b, prices are compared with it
+// rather than as floats. It returns -1, 0 or +1 like big.Rat.Cmp.
+func (a Amount) Cmp(b Amount) int {
+	return a.Decimal().Rat().Cmp(b.Decimal().Rat())
+}
+
+// Decimal is an exact decimal number. It is stored as Decimal128 and written
+// as a string in every format, so clients don't read it into a float.
+type Decimal primitive.Decimal128
+
+func ParseDecimal(s string) (Decimal, error) {
+	d, err := primitive.ParseDecimal128(s)
+	return Decimal(d), err
+}
+
+// DecimalFromRat rounds r to places decimal places, halves away from zero
+func DecimalFromRat(r *big.Rat, places int) (Decimal, error) {
+	return ParseDecimal(r.FloatString(places))
+}
+
+func (d Decimal) String() string {
+	return primitive.Decimal128(d).String()
+}
+
+// Rat returns the exact value of d, nil for NaN and infinities
+func (d Decimal) Rat() *big.Rat {
+	r, ok := new(big.Rat).SetString(d.String())
+	if !ok {
+		return nil
+	}
+
+	return r
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	*d = parsed
+	return err
+}
+
+// MarshalBinary is what the msgpack encoder looks for, it gets the text too
+func (d Decimal) MarshalBinary() ([]byte, error) {
+	return d.MarshalText()
+}
+
+func (d *Decimal) UnmarshalBinary(data []byte) error {
+	return d.UnmarshalText(data)
+}
+
+func (d Decimal) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(primitive.Decimal128(d))
+}
+
+func (d *Decimal) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t != bsontype.Decimal128 {
+		return fmt.Errorf("cannot decode %v into a decimal", t)
+	}
+
+	*d = Decimal(bson.RawValue{Type: t, Value: data}.Decimal128())
+	return nil
+}
+
+// Money is an exact amount of an ISO 4217 currency
+type Money struct {
+	Amount   Decimal `json:"amount" xml:"amount" yaml:"amount" swaggertype:"string" example:"9.99"`
+	Currency string  `json:"currency" xml:"currency" yaml:"currency" example:"EUR"`
+}
+
+type MoneyRange struct {
+	Min Money `json:"min" xml:"min" yaml:"min"`
+	Max Money `json:"max" xml:"max" yaml:"max"`
+}
+
+// ExchangeRates converts between currencies through a base currency
+type ExchangeRates struct {
+	Base string `json:"base" xml:"base" yaml:"base" validate:"required,iso4217"`
+	// how much of each currency one unit of the base buys
+	Rates      map[string]Decimal `json:"rates" xml:"-" yaml:"rates" validate:"required,dive,keys,iso4217,endkeys" swaggertype:"object,string"`
+	Updated_at time.Time          `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+}
+
+// exchangeRatesXML is ExchangeRates in XML, which has no maps, so each rate is
+// a <rate currency="USD"> element
+type exchangeRatesXML struct {
+	Base       string         `xml:"base"`
+	Rates      []exchangeRate `xml:"rates>rate"`
+	Updated_at time.Time      `xml:"updated_at"`
+}
+
+type exchangeRate struct {
+	Currency string  `xml:"currency,attr"`
+	Rate     Decimal `xml:",chardata"`
+}
+
+func (r ExchangeRates) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	out := exchangeRatesXML{Base: r.Base, Updated_at: r.Updated_at, Rates: make([]exchangeRate, 0, len(r.Rates))}
+
+	for currency, rate := range r.Rates {
+		out.Rates = append(out.Rates, exchangeRate{Currency: currency, Rate: rate})
+	}
+
+	sort.Slice(out.Rates, func(i, j int) bool { return out.Rates[i].Currency < out.Rates[j].Currency })
+
+	start.Name.Local = "ExchangeRates"
+	return e.EncodeElement(out, start)
+}
+
+func (r *ExchangeRates) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var in exchangeRatesXML
+
+	if err := d.DecodeElement(&in, &start); err != nil {
+		return err
+	}
+
+	r.Base, r.Updated_at = in.Base, in.Updated_at
+	r.Rates = make(map[string]Decimal, len(in.Rates))
+
+	for _, rate := range in.Rates {
+		r.Rates[rate.Currency] = rate.Rate
+	}
+
+	return nil
+}
+
+// AlbumV2 is the v2 representation of an album, with its prices as Money
+type AlbumV2 struct {
+	ID            primitive.ObjectID   `json:"_id" xml:"_id" yaml:"_id"`
+	Title         string               `json:"title" xml:"title" yaml:"title"`
+	Artist        string               `json:"artist" xml:"artist" yaml:"artist"`
+	ArtistID      primitive.ObjectID   `json:"artist_id" xml:"artist_id" yaml:"artist_id"`
+	Price         Money                `json:"price" xml:"price" yaml:"price"`
+	Variants      []VariantV2          `json:"variants" xml:"variants" yaml:"variants"`
+	PriceRange    *MoneyRange          `json:"price_range,omitempty" xml:"price_range,omitempty" yaml:"price_range,omitempty"`
+	Tracks        []Track              `json:"tracks" xml:"tracks" yaml:"tracks"`
+	Duration      int                  `json:"duration" xml:"duration" yaml:"duration"`
+	Genres        []primitive.ObjectID `json:"genres" xml:"genres" yaml:"genres"`
+	Tags          []string             `json:"tags" xml:"tags" yaml:"tags"`
+	ReleaseDate   string               `json:"release_date" xml:"release_date" yaml:"release_date"`
+	LabelID       primitive.ObjectID   `json:"label_id" xml:"label_id" yaml:"label_id"`
+	CatalogNumber string               `json:"catalog_number" xml:"catalog_number" yaml:"catalog_number"`
+	Barcode       string               `json:"barcode" xml:"barcode" yaml:"barcode"`
+	Format        string               `json:"format" xml:"format" yaml:"format"`
+	Country       string               `json:"country" xml:"country" yaml:"country"`
+	Weight        int                  `json:"weight" xml:"weight" yaml:"weight"`
+	Inventory     *Inventory           `json:"inventory,omitempty" xml:"inventory,omitempty" yaml:"inventory,omitempty"`
+	Rating        *Rating              `json:"rating,omitempty" xml:"rating,omitempty" yaml:"rating,omitempty"`
+	Cover         *Cover               `json:"cover,omitempty" xml:"cover,omitempty" yaml:"cover,omitempty"`
+	Created_at    time.Time            `json:"created_at" xml:"created_at" yaml:"created_at"`
+	Updated_at    time.Time            `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+	ArtistDetails *Artist              `json:"artist_details,omitempty" xml:"artist_details,omitempty" yaml:"artist_details,omitempty"`
+}
+
+type VariantV2 struct {
+	ID     primitive.ObjectID `json:"_id" xml:"_id" yaml:"_id"`
+	SKU    string             `json:"sku" xml:"sku" yaml:"sku"`
+	Format string             `json:"format" xml:"format" yaml:"format"`
+	Price  Money              `json:"price" xml:"price" yaml:"price"`
+	Stock  int                `json:"stock" xml:"stock" yaml:"stock"`
+}
+
+// AlbumsV2 is a list of v2 albums, wrapped in an <Albums> element in XML
+type AlbumsV2 []AlbumV2
+
+func (a AlbumsV2) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "Albums"
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, album := range a {
+		if err := e.Encode(album); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
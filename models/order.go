@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// order statuses, pending → paid → shipped → delivered, a pending order can
+// be cancelled and a paid one refunded
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+	OrderRefunded  = "refunded"
+)
+
+// Order is a checked out cart, with the prices it had at checkout
+type Order struct {
+	ID       primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	UserID   string             `bson:"user_id" json:"user_id" xml:"user_id" yaml:"user_id"`
+	Status   string             `json:"status" xml:"status"`
+	Items    []OrderItem        `json:"items" xml:"items" yaml:"items"`
+	Subtotal Money              `json:"subtotal" xml:"subtotal" yaml:"subtotal"`
+	Discount Money              `json:"discount" xml:"discount" yaml:"discount"`
+	Shipping Money              `json:"shipping" xml:"shipping" yaml:"shipping"`
+	Tax      Money              `json:"tax" xml:"tax" yaml:"tax"`
+	Total    Money              `json:"total" xml:"total" yaml:"total"`
+	// where the albums ship, none for digital albums only
+	ShippingAddress *Address `bson:"shipping_address,omitempty" json:"shipping_address,omitempty" xml:"shipping_address,omitempty" yaml:"shipping_address,omitempty"`
+	// the promotions the order got
+	Promotions []AppliedPromotion `json:"promotions" xml:"promotions" yaml:"promotions"`
+	// the payment of the order at the payment provider
+	PaymentID string `bson:"payment_id,omitempty" json:"payment_id,omitempty" xml:"payment_id,omitempty" yaml:"payment_id,omitempty"`
+	// a pending order is cancelled when it isn't paid by then
+	Expires_at time.Time    `json:"expires_at" xml:"expires_at"`
+	History    []OrderEvent `json:"history" xml:"history" yaml:"history"`
+	Created_at time.Time    `json:"created_at" xml:"created_at"`
+	Updated_at time.Time    `json:"updated_at" xml:"updated_at"`
+}
+
+// OrderItem is an album of an order
+type OrderItem struct {
+	AlbumID  primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Title    string             `json:"title" xml:"title" yaml:"title"`
+	Artist   string             `json:"artist" xml:"artist" yaml:"artist"`
+	Quantity int                `json:"quantity" xml:"quantity" yaml:"quantity"`
+	Price    Money              `json:"price" xml:"price" yaml:"price"`
+	Discount Money              `json:"discount" xml:"discount" yaml:"discount"`
+	Total    Money              `json:"total" xml:"total" yaml:"total"`
+	// the stock held for the item, none when the album doesn't track its stock
+	ReservationID primitive.ObjectID `bson:"reservation_id,omitempty" json:"reservation_id,omitempty" xml:"reservation_id,omitempty" yaml:"reservation_id,omitempty"`
+}
+
+// OrderEvent is a status an order went through
+type OrderEvent struct {
+	Status string    `json:"status" xml:"status" yaml:"status"`
+	At     time.Time `json:"at" xml:"at" yaml:"at"`
+}
+
+type PayOrder struct {
+	// the payment method, as tokenized by the payment provider client-side
+	PaymentToken string `json:"payment_token" xml:"payment_token" validate:"required,max=200"`
+}
+
+// Destination is where an order ships, as far as tax and shipping go
+type Destination struct {
+	Country string `json:"country" xml:"country" validate:"required,iso3166_1_alpha2"`
+	// ISO 3166-2 subdivision code without the country, e.g. CA
+	Region string `json:"region,omitempty" xml:"region,omitempty" validate:"max=3"`
+}
+
+type Address struct {
+	Name       string `json:"name" xml:"name" validate:"required,max=100"`
+	Line1      string `json:"line1" xml:"line1" validate:"required,max=200"`
+	Line2      string `json:"line2,omitempty" xml:"line2,omitempty" validate:"max=200"`
+	City       string `json:"city" xml:"city" validate:"required,max=100"`
+	PostalCode string `bson:"postal_code" json:"postal_code" xml:"postal_code" yaml:"postal_code" validate:"max=20"`
+	Region     string `json:"region,omitempty" xml:"region,omitempty" validate:"max=3"`
+	Country    string `json:"country" xml:"country" validate:"required,iso3166_1_alpha2"`
+}
+
+func (a Address) Destination() Destination {
+	return Destination{Country: a.Country, Region: a.Region}
+}
+
+type Checkout struct {
+	// required unless the albums are all digital
+	Address *Address `json:"address,omitempty" xml:"address,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// what made the price of an album change
+const (
+	PriceSourceUpdate        = "update"
+	PriceSourceBatch         = "batch"
+	PriceSourceImport        = "import"
+	PriceSourceScheduleStart = "schedule_start"
+	PriceSourceScheduleEnd   = "schedule_end"
+)
+
+// PriceChange is an entry of the album_prices history
+type PriceChange struct {
+	ID       primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	AlbumID  primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Previous Amount             `json:"previous" xml:"previous"`
+	Price    Amount             `json:"price" xml:"price"`
+	Currency string             `json:"currency" xml:"currency"`
+	Source   string             `json:"source" xml:"source"`
+	// the scheduled price behind the change, if any
+	ScheduleID primitive.ObjectID `bson:"schedule_id,omitempty" json:"schedule_id,omitempty" xml:"schedule_id,omitempty" yaml:"schedule_id,omitempty"`
+	Changed_at time.Time          `json:"changed_at" xml:"changed_at"`
+}
+
+// scheduled price statuses, a schedule without an end completes as soon as
+// it is applied
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCanceled  = "canceled"
+)
+
+// ScheduledPrice is a price an album takes from Starts_at, and gives back at
+// Ends_at when there is one
+type ScheduledPrice struct {
+	ID        primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	AlbumID   primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Price     Amount             `json:"price" xml:"price"`
+	Starts_at time.Time          `json:"starts_at" xml:"starts_at"`
+	Ends_at   *time.Time         `bson:"ends_at,omitempty" json:"ends_at,omitempty" xml:"ends_at,omitempty" yaml:"ends_at,omitempty"`
+	Status    string             `json:"status" xml:"status"`
+	// the price the album had when the schedule started, restored at the end
+	Previous   Amount     `json:"previous" xml:"previous"`
+	Created_at time.Time  `json:"created_at" xml:"created_at"`
+	Applied_at *time.Time `bson:"applied_at,omitempty" json:"applied_at,omitempty" xml:"applied_at,omitempty" yaml:"applied_at,omitempty"`
+	Ended_at   *time.Time `bson:"ended_at,omitempty" json:"ended_at,omitempty" xml:"ended_at,omitempty" yaml:"ended_at,omitempty"`
+}
+
+type AddScheduledPrice struct {
+	Price    float64    `json:"price" xml:"price" validate:"required,gt=0"`
+	StartsAt time.Time  `json:"starts_at" xml:"starts_at" validate:"required"`
+	EndsAt   *time.Time `json:"ends_at,omitempty" xml:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
+}
+
+// PriceHistory is what happened and what is planned for the price of an album
+type PriceHistory struct {
+	Currency  string           `json:"currency" xml:"currency"`
+	Changes   []PriceChange    `json:"changes" xml:"change" yaml:"changes"`
+	Scheduled []ScheduledPrice `json:"scheduled" xml:"scheduled" yaml:"scheduled"`
+}
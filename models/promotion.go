@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// promotion types
+const (
+	PromotionPercent = "percent"
+	PromotionFixed   = "fixed"
+	PromotionBOGO    = "bogo"
+)
+
+// Promotion is a discount rule. Promotions with a code only apply when the
+// code is given, the others apply by themselves.
+type Promotion struct {
+	ID   primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	Name string             `json:"name" xml:"name" validate:"required,max=100"`
+	Code string             `bson:"code,omitempty" json:"code,omitempty" xml:"code,omitempty" yaml:"code,omitempty" validate:"omitempty,alphanum,max=32"`
+	Type string             `json:"type" xml:"type" validate:"required,oneof=percent fixed bogo"`
+	// the percentage off for percent, the amount off every album for fixed
+	Value    Amount `json:"value,omitempty" xml:"value,omitempty"`
+	Currency string `json:"currency,omitempty" xml:"currency,omitempty" validate:"omitempty,iso4217"`
+	// for bogo, every Buy albums bought give Get more free
+	Buy   int            `json:"buy,omitempty" xml:"buy,omitempty" validate:"min=0"`
+	Get   int            `json:"get,omitempty" xml:"get,omitempty" validate:"min=0"`
+	Scope PromotionScope `json:"scope" xml:"scope" yaml:"scope"`
+	// a stackable promotion combines with the other stackable ones
+	Stackable bool       `json:"stackable" xml:"stackable"`
+	Starts_at *time.Time `bson:"starts_at,omitempty" json:"starts_at,omitempty" xml:"starts_at,omitempty" yaml:"starts_at,omitempty"`
+	Ends_at   *time.Time `bson:"ends_at,omitempty" json:"ends_at,omitempty" xml:"ends_at,omitempty" yaml:"ends_at,omitempty"`
+	// how many orders may use the promotion, in all and per user, 0 for no limit
+	UsageLimit   int       `bson:"usage_limit" json:"usage_limit" xml:"usage_limit" yaml:"usage_limit" validate:"min=0"`
+	PerUserLimit int       `bson:"per_user_limit" json:"per_user_limit" xml:"per_user_limit" yaml:"per_user_limit" validate:"min=0"`
+	Uses         int       `bson:"uses,omitempty" json:"uses" xml:"uses" yaml:"uses"`
+	Created_at   time.Time `json:"created_at" xml:"created_at"`
+	Updated_at   time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// PromotionScope limits a promotion to the albums of any of its artists or
+// genres, sub-genres included, or to its albums. An empty scope is the
+// whole catalog.
+type PromotionScope struct {
+	Artists []primitive.ObjectID `json:"artists,omitempty" xml:"artists,omitempty" yaml:"artists,omitempty"`
+	Genres  []primitive.ObjectID `json:"genres,omitempty" xml:"genres,omitempty" yaml:"genres,omitempty"`
+	Albums  []primitive.ObjectID `json:"albums,omitempty" xml:"albums,omitempty" yaml:"albums,omitempty"`
+}
+
+type AddPromotion struct {
+	Name string `json:"name" xml:"name"`
+	// customers type it at checkout, leave it empty for a promotion that
+	// applies by itself
+	Code         string         `json:"code,omitempty" xml:"code,omitempty"`
+	Type         string         `json:"type" xml:"type" example:"percent"`
+	Value        float64        `json:"value,omitempty" xml:"value,omitempty"`
+	Currency     string         `json:"currency,omitempty" xml:"currency,omitempty"`
+	Buy          int            `json:"buy,omitempty" xml:"buy,omitempty"`
+	Get          int            `json:"get,omitempty" xml:"get,omitempty"`
+	Scope        PromotionScope `json:"scope" xml:"scope"`
+	Stackable    bool           `json:"stackable" xml:"stackable"`
+	StartsAt     *time.Time     `json:"starts_at,omitempty" xml:"starts_at,omitempty"`
+	EndsAt       *time.Time     `json:"ends_at,omitempty" xml:"ends_at,omitempty"`
+	UsageLimit   int            `json:"usage_limit" xml:"usage_limit"`
+	PerUserLimit int            `json:"per_user_limit" xml:"per_user_limit"`
+}
+
+// Redemption is the use of a promotion by an order
+type Redemption struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	PromotionID primitive.ObjectID `bson:"promotion_id" json:"promotion_id" xml:"promotion_id" yaml:"promotion_id"`
+	UserID      string             `bson:"user_id" json:"user_id" xml:"user_id" yaml:"user_id"`
+	OrderID     primitive.ObjectID `bson:"order_id" json:"order_id" xml:"order_id" yaml:"order_id"`
+	Created_at  time.Time          `json:"created_at" xml:"created_at"`
+}
+
+type QuoteRequest struct {
+	Items []QuoteRequestItem `json:"items" xml:"items" validate:"required,min=1,max=100,dive"`
+	// promotion codes
+	Codes []string `json:"codes,omitempty" xml:"codes,omitempty" validate:"max=5,dive,required,max=32"`
+	// where the albums would ship, to add shipping and tax
+	Destination *Destination `json:"destination,omitempty" xml:"destination,omitempty"`
+	// ISO 4217 currency of the quote, the currency of the albums by default
+	Currency string `json:"currency,omitempty" xml:"currency,omitempty" validate:"omitempty,iso4217"`
+}
+
+type QuoteRequestItem struct {
+	AlbumID  string `json:"album_id" xml:"album_id" validate:"required"`
+	Quantity int    `json:"quantity" xml:"quantity" validate:"required,min=1,max=99"`
+}
+
+// Quote is what a set of albums costs with the promotions it gets
+type Quote struct {
+	Items    []QuoteLine `json:"items" xml:"items" yaml:"items"`
+	Subtotal Money       `json:"subtotal" xml:"subtotal" yaml:"subtotal"`
+	Discount Money       `json:"discount" xml:"discount" yaml:"discount"`
+	Shipping Money       `json:"shipping" xml:"shipping" yaml:"shipping"`
+	Tax      Money       `json:"tax" xml:"tax" yaml:"tax"`
+	Total    Money       `json:"total" xml:"total" yaml:"total"`
+	// the name of the tax, e.g. VAT
+	TaxName    string             `json:"tax_name,omitempty" xml:"tax_name,omitempty" yaml:"tax_name,omitempty"`
+	Promotions []AppliedPromotion `json:"promotions" xml:"promotions" yaml:"promotions"`
+	// the codes given that don't apply, and why
+	Rejected []RejectedCode `json:"rejected,omitempty" xml:"rejected,omitempty" yaml:"rejected,omitempty"`
+}
+
+type QuoteLine struct {
+	AlbumID  primitive.ObjectID `json:"album_id" xml:"album_id" yaml:"album_id"`
+	Quantity int                `json:"quantity" xml:"quantity" yaml:"quantity"`
+	Price    Money              `json:"price" xml:"price" yaml:"price"`
+	Subtotal Money              `json:"subtotal" xml:"subtotal" yaml:"subtotal"`
+	Discount Money              `json:"discount" xml:"discount" yaml:"discount"`
+	Total    Money              `json:"total" xml:"total" yaml:"total"`
+}
+
+// AppliedPromotion is the discount a promotion gives
+type AppliedPromotion struct {
+	PromotionID primitive.ObjectID `bson:"promotion_id" json:"promotion_id" xml:"promotion_id" yaml:"promotion_id"`
+	Name        string             `json:"name" xml:"name" yaml:"name"`
+	Code        string             `bson:"code,omitempty" json:"code,omitempty" xml:"code,omitempty" yaml:"code,omitempty"`
+	Discount    Money              `json:"discount" xml:"discount" yaml:"discount"`
+}
+
+type RejectedCode struct {
+	Code   string `json:"code" xml:"code" yaml:"code"`
+	Reason string `json:"reason" xml:"reason" yaml:"reason"`
+}
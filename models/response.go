@@ -1,9 +1,9 @@
 package models
 
 type SuccessMessage struct {
-	Message string `json:"message"`
+	Message string `json:"message" xml:"message"`
 }
 
 type ErrorMessage struct {
-	Error string `json:"error"`
+	Error string `json:"error" xml:"error"`
 }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// review moderation statuses, flagged reviews are hidden and left out of the
+// rating of the album
+const (
+	ReviewPublished = "published"
+	ReviewApproved  = "approved"
+	ReviewFlagged   = "flagged"
+)
+
+// Review is what a user thinks of an album, one per user and album
+type Review struct {
+	ID      primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	AlbumID primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	UserID  string             `bson:"user_id" json:"user_id" xml:"user_id" yaml:"user_id"`
+	// 1 to 5 stars
+	Rating     int       `json:"rating" xml:"rating"`
+	Title      string    `json:"title" xml:"title"`
+	Body       string    `json:"body" xml:"body"`
+	Status     string    `json:"status" xml:"status"`
+	Created_at time.Time `json:"created_at" xml:"created_at"`
+	Updated_at time.Time `json:"updated_at" xml:"updated_at"`
+	// when a moderator last set the status
+	Moderated_at *time.Time `bson:"moderated_at,omitempty" json:"moderated_at,omitempty" xml:"moderated_at,omitempty" yaml:"moderated_at,omitempty"`
+}
+
+type AddReview struct {
+	Rating int    `json:"rating" xml:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title,omitempty" xml:"title,omitempty" validate:"max=100"`
+	Body   string `json:"body,omitempty" xml:"body,omitempty" validate:"max=5000"`
+}
+
+// UpdateReview changes the fields given, an approved review goes back to
+// published
+type UpdateReview struct {
+	Rating *int    `json:"rating,omitempty" xml:"rating,omitempty" validate:"omitempty,min=1,max=5"`
+	Title  *string `json:"title,omitempty" xml:"title,omitempty" validate:"omitempty,max=100"`
+	Body   *string `json:"body,omitempty" xml:"body,omitempty" validate:"omitempty,max=5000"`
+}
+
+type ModerateReview struct {
+	Status string `json:"status" xml:"status" validate:"required,oneof=published approved flagged"`
+}
+
+// Rating sums up the reviews of an album that aren't flagged
+type Rating struct {
+	// rounded to 2 decimals
+	Average float64 `json:"average" xml:"average"`
+	Count   int     `json:"count" xml:"count"`
+}
@@ -0,0 +1,13 @@
+package models
+
+type SearchHit struct {
+	Album      `bson:",inline" yaml:",inline"`
+	Score      float64          `bson:"score" json:"score" xml:"score"`
+	Highlights SearchHighlights `bson:"-" json:"highlights" xml:"highlights"`
+}
+
+// matched words wrapped in <em> tags, the rest of the text is HTML escaped
+type SearchHighlights struct {
+	Title  string `json:"title" xml:"title"`
+	Artist string `json:"artist" xml:"artist"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// PriceStats are the price statistics of the albums of one currency
+type PriceStats struct {
+	Currency string `bson:"_id" json:"currency" xml:"currency"`
+	Count    int    `bson:"count" json:"count" xml:"count"`
+	Min      Amount `bson:"min" json:"min" xml:"min"`
+	Max      Amount `bson:"max" json:"max" xml:"max"`
+	Avg      Amount `bson:"avg" json:"avg" xml:"avg"`
+	Median   Amount `bson:"-" json:"median" xml:"median"`
+}
+
+type PeriodCount struct {
+	// 2006-01-02 for days, 2006-W01 (ISO week) for weeks and 2006-01 for months
+	Period string `bson:"_id" json:"period" xml:"period"`
+	Count  int    `bson:"count" json:"count" xml:"count"`
+}
+
+type CreationStats struct {
+	PerDay   []PeriodCount `json:"per_day" xml:"day" yaml:"per_day"`
+	PerWeek  []PeriodCount `json:"per_week" xml:"week" yaml:"per_week"`
+	PerMonth []PeriodCount `json:"per_month" xml:"month" yaml:"per_month"`
+}
+
+type AlbumStats struct {
+	Total   int          `json:"total" xml:"total"`
+	Artists []FacetCount `json:"artists" xml:"artist"`
+	// one entry per currency, the most used first
+	Prices  []PriceStats  `json:"prices" xml:"price" yaml:"prices"`
+	Created CreationStats `json:"created" xml:"created"`
+}
@@ -0,0 +1,7 @@
+package models
+
+type Suggestion struct {
+	Value string `bson:"value" json:"value" xml:"value"`
+	// number of albums sharing the value
+	Count int `bson:"count" json:"count" xml:"count"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/xml"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// track is a song of an album, stored inside the album document.
+type Track struct {
+	ID     primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	Number int                `json:"number" xml:"number" validate:"required,min=1"`
+	Title  string             `json:"title" xml:"title" validate:"required"`
+	// length in seconds
+	Duration int `json:"duration" xml:"duration" validate:"min=0"`
+	// International Standard Recording Code, stored without hyphens
+	ISRC     string `json:"isrc,omitempty" xml:"isrc,omitempty" yaml:"isrc,omitempty" validate:"omitempty,isrc"`
+	Explicit bool   `json:"explicit" xml:"explicit"`
+}
+
+// Tracks is the track list of an album, wrapped in a <Tracks> element in XML
+type Tracks []Track
+
+func (t Tracks) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "Tracks"
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, track := range t {
+		if err := e.Encode(track); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+type AddTrack struct {
+	Number   int    `json:"number" xml:"number"`
+	Title    string `json:"title" xml:"title"`
+	Duration int    `json:"duration" xml:"duration"`
+	ISRC     string `json:"isrc,omitempty" xml:"isrc,omitempty"`
+	Explicit bool   `json:"explicit" xml:"explicit"`
+}
+
+// PreviewURL is a signed link to the preview clip of a track
+type PreviewURL struct {
+	URL        string    `json:"url" xml:"url"`
+	Expires_at time.Time `json:"expires_at" xml:"expires_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserToken lets requests name the user in the X-User-ID header, it is sent
+// as the X-User-Token header until it expires
+type UserToken struct {
+	UserID     string    `json:"user_id" xml:"user_id" yaml:"user_id"`
+	Token      string    `json:"token" xml:"token" yaml:"token"`
+	Expires_at time.Time `json:"expires_at" xml:"expires_at" yaml:"expires_at"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/xml"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// variant is a sellable edition of an album, e.g. the vinyl or the CD,
+// stored inside the album document.
+type Variant struct {
+	ID primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	// stock keeping unit, unique across the catalog
+	SKU      string `json:"sku" xml:"sku" validate:"required,max=64"`
+	Format   string `json:"format" xml:"format" validate:"required,oneof=cd vinyl digital"`
+	Price    Amount `json:"price" xml:"price" validate:"required,gt=0"`
+	Currency string `json:"currency" xml:"currency" validate:"required,iso4217"`
+	Stock    int    `json:"stock" xml:"stock" validate:"min=0"`
+}
+
+// Variants is the variant list of an album, wrapped in a <Variants> element in XML
+type Variants []Variant
+
+func (v Variants) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "Variants"
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, variant := range v {
+		if err := e.Encode(variant); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+type AddVariant struct {
+	SKU    string  `json:"sku" xml:"sku"`
+	Format string  `json:"format" xml:"format"`
+	Price  float64 `json:"price" xml:"price"`
+	// ISO 4217 code, the variants of an album share one currency
+	Currency string `json:"currency" xml:"currency"`
+	Stock    int    `json:"stock" xml:"stock"`
+}
+
+// PriceRange is the cheapest and the dearest variant of an album
+type PriceRange struct {
+	Min      Amount `json:"min" xml:"min"`
+	Max      Amount `json:"max" xml:"max"`
+	Currency string `json:"currency" xml:"currency"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Wishlist is the albums a user saved for later. A public wishlist can be
+// read by anyone with its share token.
+type Wishlist struct {
+	ID     primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	UserID string             `bson:"user_id" json:"user_id" xml:"user_id" yaml:"user_id"`
+	Items  []WishlistItem     `json:"items" xml:"items" yaml:"items"`
+	Public bool               `json:"public" xml:"public"`
+	// set while the wishlist is public, a new one every time it is shared again
+	ShareToken string    `bson:"share_token,omitempty" json:"share_token,omitempty" xml:"share_token,omitempty" yaml:"share_token,omitempty"`
+	Created_at time.Time `json:"created_at" xml:"created_at"`
+	Updated_at time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// WishlistItem is an album of a wishlist, with the price it had when it was
+// saved
+type WishlistItem struct {
+	AlbumID  primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Price    Amount             `json:"price" xml:"price"`
+	Currency string             `json:"currency" xml:"currency"`
+	Added_at time.Time          `json:"added_at" xml:"added_at"`
+}
+
+type AddWishlistItem struct {
+	AlbumID string `json:"album_id" xml:"album_id" validate:"required"`
+}
+
+type WishlistSharing struct {
+	Public *bool `json:"public" xml:"public" validate:"required"`
+}
+
+// WishlistView is a wishlist with the albums as they are now, the albums
+// deleted since they were saved are left out
+type WishlistView struct {
+	Items      []WishlistLine `json:"items" xml:"items" yaml:"items"`
+	Public     bool           `json:"public" xml:"public" yaml:"public"`
+	ShareToken string         `json:"share_token,omitempty" xml:"share_token,omitempty" yaml:"share_token,omitempty"`
+	Updated_at time.Time      `json:"updated_at" xml:"updated_at" yaml:"updated_at"`
+}
+
+type WishlistLine struct {
+	AlbumID primitive.ObjectID `json:"album_id" xml:"album_id" yaml:"album_id"`
+	Title   string             `json:"title" xml:"title" yaml:"title"`
+	Artist  string             `json:"artist" xml:"artist" yaml:"artist"`
+	Price   Money              `json:"price" xml:"price" yaml:"price"`
+	// the price when the album was saved
+	AddedPrice   Money     `json:"added_price" xml:"added_price" yaml:"added_price"`
+	PriceDropped bool      `json:"price_dropped" xml:"price_dropped" yaml:"price_dropped"`
+	Added_at     time.Time `json:"added_at" xml:"added_at" yaml:"added_at"`
+}
+
+// notification types
+const (
+	NotificationPriceDrop = "price_drop"
+)
+
+// Notification tells a user something happened, for now that the price of
+// an album of their wishlist dropped
+type Notification struct {
+	ID       primitive.ObjectID `bson:"_id" json:"_id" xml:"_id" yaml:"_id"`
+	UserID   string             `bson:"user_id" json:"user_id" xml:"user_id" yaml:"user_id"`
+	Type     string             `json:"type" xml:"type"`
+	AlbumID  primitive.ObjectID `bson:"album_id" json:"album_id" xml:"album_id" yaml:"album_id"`
+	Title    string             `json:"title" xml:"title"`
+	Previous Amount             `json:"previous" xml:"previous"`
+	Price    Amount             `json:"price" xml:"price"`
+	Currency string             `json:"currency" xml:"currency"`
+	Read     bool               `json:"read" xml:"read"`
+	// notifications are removed 90 days after
+	Created_at time.Time `json:"created_at" xml:"created_at"`
+}
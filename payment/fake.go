@@ -0,0 +1,73 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// DeclinedToken is the token the fake provider always declines
+const DeclinedToken = "tok_declined"
+
+// Fake is a PaymentProvider that keeps its payments in memory, for local
+// runs and tests. It accepts every token except DeclinedToken.
+type Fake struct {
+	mu       sync.Mutex
+	payments map[string]Payment
+	refunded map[string]bool
+}
+
+func NewFake() *Fake {
+	return &Fake{payments: map[string]Payment{}, refunded: map[string]bool{}}
+}
+
+func (f *Fake) Charge(ctx context.Context, charge Charge) (Payment, error) {
+	if charge.Token == "" || charge.Token == DeclinedToken {
+		return Payment{}, ErrDeclined
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return Payment{}, err
+	}
+
+	payment := Payment{
+		ID:         "fake_" + hex.EncodeToString(id),
+		Reference:  charge.Reference,
+		Amount:     charge.Amount,
+		Created_at: time.Now(),
+	}
+
+	f.mu.Lock()
+	f.payments[payment.ID] = payment
+	f.mu.Unlock()
+
+	return payment, nil
+}
+
+func (f *Fake) Refund(ctx context.Context, paymentID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.payments[paymentID]; !ok {
+		return ErrNotFound
+	}
+
+	if f.refunded[paymentID] {
+		return ErrAlreadyRefunded
+	}
+
+	f.refunded[paymentID] = true
+
+	return nil
+}
+
+// Refunded tells whether the payment was refunded
+func (f *Fake) Refunded(paymentID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.refunded[paymentID]
+}
@@ -0,0 +1,55 @@
+package payment_test
+
+import (
+	"context"
+	"rest/models"
+	"rest/payment"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+
+	var provider payment.PaymentProvider = payment.NewFake()
+	ctx := context.Background()
+	amount, _ := models.ParseDecimal("19.99")
+	charge := payment.Charge{Reference: "order", Amount: models.Money{Amount: amount, Currency: "EUR"}}
+
+	test_cases := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "accepted", token: "tok_visa"},
+		{name: "declined", token: payment.DeclinedToken, err: payment.ErrDeclined},
+		{name: "no payment method", token: "", err: payment.ErrDeclined},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			charge.Token = tc.token
+			paid, err := provider.Charge(ctx, charge)
+
+			assert.Equal(t, tc.err, err)
+
+			if err == nil {
+				assert.NotEmpty(t, paid.ID)
+				assert.Equal(t, charge.Amount, paid.Amount)
+			}
+		})
+	}
+}
+
+func TestFakeRefund(t *testing.T) {
+
+	fake := payment.NewFake()
+	ctx := context.Background()
+	paid, _ := fake.Charge(ctx, payment.Charge{Reference: "order", Token: "tok_visa"})
+
+	assert.False(t, fake.Refunded(paid.ID))
+	assert.NoError(t, fake.Refund(ctx, paid.ID))
+	assert.True(t, fake.Refunded(paid.ID))
+	assert.Equal(t, payment.ErrAlreadyRefunded, fake.Refund(ctx, paid.ID))
+	assert.Equal(t, payment.ErrNotFound, fake.Refund(ctx, "missing"))
+}
@@ -0,0 +1,40 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"rest/models"
+	"time"
+)
+
+var (
+	ErrDeclined        = errors.New("payment declined")
+	ErrNotFound        = errors.New("payment not found")
+	ErrAlreadyRefunded = errors.New("payment already refunded")
+)
+
+// PaymentProvider takes the money of orders, and gives it back
+type PaymentProvider interface {
+	// Charge takes the amount with the payment method the token stands for.
+	// It returns ErrDeclined when the payment method is refused.
+	Charge(ctx context.Context, charge Charge) (Payment, error)
+	// Refund gives the whole amount of a payment back
+	Refund(ctx context.Context, paymentID string) error
+}
+
+// Charge is a payment to take for an order
+type Charge struct {
+	// what the payment is for, the order ID
+	Reference string
+	Amount    models.Money
+	// the payment method, as tokenized by the provider client-side
+	Token string
+}
+
+// Payment is a successful charge
+type Payment struct {
+	ID         string
+	Reference  string
+	Amount     models.Money
+	Created_at time.Time
+}
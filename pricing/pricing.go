@@ -0,0 +1,186 @@
+package pricing
+
+import (
+	"math/big"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rule types
+const (
+	Percent = "percent"
+	Fixed   = "fixed"
+	BOGO    = "bogo"
+)
+
+// Line is a quantity of an album at its unit price
+type Line struct {
+	AlbumID  primitive.ObjectID
+	ArtistID primitive.ObjectID
+	Genres   []primitive.ObjectID
+	Quantity int
+	Price    *big.Rat
+}
+
+// Rule is a promotion, with its amounts in the currency of the lines
+type Rule struct {
+	ID   primitive.ObjectID
+	Type string
+	// the percentage off for Percent, the amount off every unit for Fixed
+	Value *big.Rat
+	// for BOGO, every Buy units bought give Get more units free
+	Buy, Get int
+	// the rule applies to the albums of any of these artists or genres, or
+	// to these albums, and to every album when they are all empty
+	Artists []primitive.ObjectID
+	Genres  []primitive.ObjectID
+	Albums  []primitive.ObjectID
+	// a stackable rule combines with the other stackable rules, the others
+	// apply alone
+	Stackable bool
+}
+
+// Applied is the discount a rule gives
+type Applied struct {
+	ID       primitive.ObjectID
+	Discount *big.Rat
+}
+
+// Result is the best discount the rules give to the lines
+type Result struct {
+	// the discount of every line, in the order of the lines
+	Lines []*big.Rat
+	// the rules that give a discount, in the order they were given
+	Applied []Applied
+	Total   *big.Rat
+}
+
+// Apply picks the combination of rules that gives the largest discount:
+// either all the stackable rules together, or one of the others alone.
+// Discounts are rounded to places decimals and never exceed a line.
+func Apply(lines []Line, rules []Rule, places int) Result {
+	var stackable []Rule
+
+	for _, rule := range rules {
+		if rule.Stackable {
+			stackable = append(stackable, rule)
+		}
+	}
+
+	best := evaluate(lines, stackable, places)
+
+	for _, rule := range rules {
+		if rule.Stackable {
+			continue
+		}
+
+		if result := evaluate(lines, []Rule{rule}, places); result.Total.Cmp(best.Total) > 0 {
+			best = result
+		}
+	}
+
+	return best
+}
+
+// Applies tells whether the rule gives a discount to the lines on its own
+func Applies(lines []Line, rule Rule, places int) bool {
+	return evaluate(lines, []Rule{rule}, places).Total.Sign() > 0
+}
+
+// evaluate applies the rules one after the other, each one on what the
+// previous ones left of every line
+func evaluate(lines []Line, rules []Rule, places int) Result {
+	result := Result{Lines: make([]*big.Rat, len(lines)), Total: new(big.Rat)}
+
+	for i := range lines {
+		result.Lines[i] = new(big.Rat)
+	}
+
+	for _, rule := range rules {
+		applied := new(big.Rat)
+
+		for i, line := range lines {
+			if !rule.matches(line) {
+				continue
+			}
+
+			subtotal := new(big.Rat).Mul(line.Price, big.NewRat(int64(line.Quantity), 1))
+			left := new(big.Rat).Sub(subtotal, result.Lines[i])
+
+			discount := round(rule.discount(line, subtotal), places)
+			if discount.Cmp(left) > 0 {
+				discount = left
+			}
+
+			if discount.Sign() <= 0 {
+				continue
+			}
+
+			result.Lines[i].Add(result.Lines[i], discount)
+			applied.Add(applied, discount)
+		}
+
+		if applied.Sign() > 0 {
+			result.Applied = append(result.Applied, Applied{ID: rule.ID, Discount: applied})
+			result.Total.Add(result.Total, applied)
+		}
+	}
+
+	return result
+}
+
+func (r Rule) matches(line Line) bool {
+	if len(r.Artists) == 0 && len(r.Genres) == 0 && len(r.Albums) == 0 {
+		return true
+	}
+
+	if contains(r.Albums, line.AlbumID) || contains(r.Artists, line.ArtistID) {
+		return true
+	}
+
+	for _, genre := range line.Genres {
+		if contains(r.Genres, genre) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Rule) discount(line Line, subtotal *big.Rat) *big.Rat {
+	switch r.Type {
+	case Percent:
+		discount := new(big.Rat).Mul(subtotal, r.Value)
+		return discount.Quo(discount, big.NewRat(100, 1))
+	case Fixed:
+		off := r.Value
+		if off.Cmp(line.Price) > 0 {
+			off = line.Price
+		}
+		return new(big.Rat).Mul(off, big.NewRat(int64(line.Quantity), 1))
+	case BOGO:
+		if r.Buy <= 0 || r.Get <= 0 {
+			return new(big.Rat)
+		}
+		free := line.Quantity / (r.Buy + r.Get) * r.Get
+		return new(big.Rat).Mul(line.Price, big.NewRat(int64(free), 1))
+	}
+
+	return new(big.Rat)
+}
+
+func contains(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// round rounds r to places decimals, halves away from zero
+func round(r *big.Rat, places int) *big.Rat {
+	rounded, _ := new(big.Rat).SetString(r.FloatString(places))
+	return rounded
+}
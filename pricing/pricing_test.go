@@ -0,0 +1,111 @@
+package pricing_test
+
+import (
+	"math/big"
+	"rest/pricing"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestApply(t *testing.T) {
+
+	artist := primitive.NewObjectID()
+	genre := primitive.NewObjectID()
+
+	lines := []pricing.Line{
+		{AlbumID: primitive.NewObjectID(), ArtistID: artist, Quantity: 2, Price: big.NewRat(10, 1)},
+		{AlbumID: primitive.NewObjectID(), Genres: []primitive.ObjectID{genre}, Quantity: 3, Price: big.NewRat(999, 100)},
+	}
+
+	percent := pricing.Rule{ID: primitive.NewObjectID(), Type: pricing.Percent, Value: big.NewRat(20, 1), Artists: []primitive.ObjectID{artist}, Stackable: true}
+	fixed := pricing.Rule{ID: primitive.NewObjectID(), Type: pricing.Fixed, Value: big.NewRat(1, 1), Stackable: true}
+	bogo := pricing.Rule{ID: primitive.NewObjectID(), Type: pricing.BOGO, Buy: 1, Get: 1, Genres: []primitive.ObjectID{genre}}
+	huge := pricing.Rule{ID: primitive.NewObjectID(), Type: pricing.Fixed, Value: big.NewRat(50, 1), Albums: []primitive.ObjectID{lines[0].AlbumID}, Stackable: true}
+	small := pricing.Rule{ID: primitive.NewObjectID(), Type: pricing.Percent, Value: big.NewRat(10, 1)}
+	elsewhere := pricing.Rule{ID: primitive.NewObjectID(), Type: pricing.Percent, Value: big.NewRat(50, 1), Artists: []primitive.ObjectID{primitive.NewObjectID()}}
+
+	test_cases := []struct {
+		name    string
+		rules   []pricing.Rule
+		lines   []string
+		applied []primitive.ObjectID
+		total   string
+	}{
+		{
+			name:  "no rules",
+			lines: []string{"0.00", "0.00"},
+			total: "0.00",
+		},
+		{
+			name:    "percent of an artist",
+			rules:   []pricing.Rule{percent},
+			lines:   []string{"4.00", "0.00"},
+			applied: []primitive.ObjectID{percent.ID},
+			total:   "4.00",
+		},
+		{
+			name:    "stackable rules add up",
+			rules:   []pricing.Rule{percent, fixed},
+			lines:   []string{"6.00", "3.00"},
+			applied: []primitive.ObjectID{percent.ID, fixed.ID},
+			total:   "9.00",
+		},
+		{
+			name:    "a rule that doesn't stack wins when it gives more",
+			rules:   []pricing.Rule{fixed, bogo},
+			lines:   []string{"0.00", "9.99"},
+			applied: []primitive.ObjectID{bogo.ID},
+			total:   "9.99",
+		},
+		{
+			name:    "the stackable rules win when they give more",
+			rules:   []pricing.Rule{percent, fixed, small},
+			lines:   []string{"6.00", "3.00"},
+			applied: []primitive.ObjectID{percent.ID, fixed.ID},
+			total:   "9.00",
+		},
+		{
+			name:    "a discount never exceeds the line",
+			rules:   []pricing.Rule{percent, huge},
+			lines:   []string{"20.00", "0.00"},
+			applied: []primitive.ObjectID{percent.ID, huge.ID},
+			total:   "20.00",
+		},
+		{
+			name:  "a rule out of scope gives nothing",
+			rules: []pricing.Rule{elsewhere},
+			lines: []string{"0.00", "0.00"},
+			total: "0.00",
+		},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := pricing.Apply(lines, tc.rules, 2)
+
+			var got []string
+			for _, line := range result.Lines {
+				got = append(got, line.FloatString(2))
+			}
+
+			var applied []primitive.ObjectID
+			for _, a := range result.Applied {
+				applied = append(applied, a.ID)
+			}
+
+			assert.Equal(t, tc.lines, got)
+			assert.Equal(t, tc.applied, applied)
+			assert.Equal(t, tc.total, result.Total.FloatString(2))
+		})
+	}
+}
+
+func TestApplies(t *testing.T) {
+
+	lines := []pricing.Line{{AlbumID: primitive.NewObjectID(), Quantity: 1, Price: big.NewRat(10, 1)}}
+
+	assert.True(t, pricing.Applies(lines, pricing.Rule{Type: pricing.Percent, Value: big.NewRat(10, 1)}, 2))
+	assert.False(t, pricing.Applies(lines, pricing.Rule{Type: pricing.BOGO, Buy: 1, Get: 1}, 2))
+}
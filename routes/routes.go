@@ -1,7 +1,9 @@
 package routes
 
 import (
+	"net/http"
 	"rest/controller"
+	"rest/middlewares"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -10,20 +12,187 @@ import (
 
 func Routes() *gin.Engine {
 	router := gin.Default()
+	router.Use(middlewares.Compress(1024))
 
 	v1 := router.Group("/api/v1")
 	{
 		albums := v1.Group("/albums")
 		{
 			albums.GET(":id", controller.GetAlbumByID)
+			albums.GET("export", controller.ExportAlbums)
+			albums.GET("search", controller.SearchAlbums)
+			albums.GET("suggest", controller.SuggestAlbums)
+			albums.GET("stats", controller.GetAlbumStats)
 			albums.GET("", controller.GetAlbums)
 			albums.POST("", controller.PostAlbum)
 			albums.PATCH(":id", controller.UpdateAlbum)
 			albums.DELETE(":id", controller.DeleteAlbumByID)
+
+			albums.GET(":id/cover", controller.GetAlbumCover)
+			albums.PUT(":id/cover", controller.PutAlbumCover)
+
+			albums.GET(":id/tracks", controller.GetAlbumTracks)
+			albums.GET(":id/tracks/:track", controller.GetAlbumTrack)
+			albums.POST(":id/tracks", controller.PostAlbumTrack)
+			albums.PATCH(":id/tracks/:track", controller.UpdateAlbumTrack)
+			albums.DELETE(":id/tracks/:track", controller.DeleteAlbumTrack)
+
+			albums.GET(":id/price-history", controller.GetAlbumPriceHistory)
+			albums.POST(":id/price-schedules", controller.PostAlbumPriceSchedule)
+			albums.DELETE(":id/price-schedules/:schedule", controller.DeleteAlbumPriceSchedule)
+
+			albums.GET(":id/inventory", controller.GetAlbumInventory)
+			albums.PATCH(":id/inventory", controller.UpdateAlbumInventory)
+			albums.POST(":id/inventory/adjustments", controller.AdjustAlbumInventory)
+			albums.GET(":id/inventory/movements", controller.GetAlbumInventoryMovements)
+			albums.POST(":id/reservations", controller.PostAlbumReservation)
+
+			albums.GET(":id/variants", controller.GetAlbumVariants)
+			albums.GET(":id/variants/:variant", controller.GetAlbumVariant)
+			albums.POST(":id/variants", controller.PostAlbumVariant)
+			albums.PATCH(":id/variants/:variant", controller.UpdateAlbumVariant)
+			albums.DELETE(":id/variants/:variant", controller.DeleteAlbumVariant)
+
+			albums.GET(":id/reviews", controller.GetAlbumReviews)
+			albums.GET(":id/reviews/:review", controller.GetAlbumReview)
+			albums.POST(":id/reviews", controller.PostAlbumReview)
+			albums.PATCH(":id/reviews/:review", controller.UpdateAlbumReview)
+			albums.DELETE(":id/reviews/:review", controller.DeleteAlbumReview)
+			albums.PUT(":id/reviews/:review/moderation", controller.ModerateAlbumReview)
+
+			albums.GET(":id/tracks/:track/preview", controller.GetTrackPreview)
+			albums.GET(":id/tracks/:track/preview/url", controller.GetTrackPreviewURL)
+			albums.PUT(":id/tracks/:track/preview", controller.PutTrackPreview)
+		}
+
+		v1.POST("/albums:method", customMethods(map[string]gin.HandlerFunc{
+			":batchCreate": controller.BatchCreateAlbums,
+			":batchDelete": controller.BatchDeleteAlbums,
+			":import":      controller.ImportAlbums,
+		}))
+		v1.PATCH("/albums:method", customMethods(map[string]gin.HandlerFunc{
+			":batchUpdate": controller.BatchUpdateAlbums,
+		}))
+
+		genres := v1.Group("/genres")
+		{
+			genres.GET(":id", controller.GetGenreByID)
+			genres.GET("", controller.GetGenres)
+			genres.POST("", controller.PostGenre)
+			genres.PATCH(":id", controller.UpdateGenre)
+			genres.DELETE(":id", controller.DeleteGenreByID)
+		}
+
+		v1.POST("/users/:id/token", controller.PostUserToken)
+
+		cart := v1.Group("/cart")
+		{
+			cart.GET("", controller.GetCart)
+			cart.DELETE("", controller.DeleteCart)
+			cart.POST("items", controller.PostCartItem)
+			cart.PATCH("items/:album", controller.UpdateCartItem)
+			cart.DELETE("items/:album", controller.DeleteCartItem)
+			cart.POST("merge", controller.MergeCart)
+		}
+
+		wishlist := v1.Group("/wishlist")
+		{
+			wishlist.GET("", controller.GetWishlist)
+			wishlist.DELETE("", controller.DeleteWishlist)
+			wishlist.POST("items", controller.PostWishlistItem)
+			wishlist.DELETE("items/:album", controller.DeleteWishlistItem)
+			wishlist.PUT("sharing", controller.PutWishlistSharing)
+		}
+
+		v1.GET("/wishlists/:token", controller.GetSharedWishlist)
+
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("", controller.GetNotifications)
+			notifications.POST(":id/read", controller.ReadNotification)
+		}
+
+		orders := v1.Group("/orders")
+		{
+			orders.GET("", controller.GetOrders)
+			orders.POST("", controller.PostOrder)
+			orders.GET(":id", controller.GetOrderByID)
+			orders.POST(":id/pay", controller.PayOrder)
+			orders.POST(":id/cancel", controller.CancelOrder)
+			orders.POST(":id/ship", controller.ShipOrder)
+			orders.POST(":id/deliver", controller.DeliverOrder)
+			orders.POST(":id/refund", controller.RefundOrder)
+		}
+
+		promotions := v1.Group("/promotions")
+		{
+			promotions.GET("", controller.GetPromotions)
+			promotions.GET(":id", controller.GetPromotionByID)
+			promotions.POST("", controller.PostPromotion)
+			promotions.PATCH(":id", controller.UpdatePromotion)
+			promotions.DELETE(":id", controller.DeletePromotionByID)
+		}
+
+		v1.POST("/pricing/quote", controller.PostQuote)
+
+		reservations := v1.Group("/reservations")
+		{
+			reservations.GET(":id", controller.GetReservation)
+			reservations.POST(":id/commit", controller.CommitReservation)
+			reservations.POST(":id/release", controller.ReleaseReservation)
+		}
+
+		labels := v1.Group("/labels")
+		{
+			labels.GET(":id", controller.GetLabelByID)
+			labels.GET("", controller.GetLabels)
+			labels.POST("", controller.PostLabel)
+			labels.PATCH(":id", controller.UpdateLabel)
+			labels.DELETE(":id", controller.DeleteLabelByID)
+		}
+
+		v1.GET("/tags", controller.GetTags)
+		v1.POST("/tags:method", customMethods(map[string]gin.HandlerFunc{
+			":rename": controller.RenameTag,
+			":merge":  controller.MergeTags,
+		}))
+
+		artists := v1.Group("/artists")
+		{
+			artists.GET(":id", controller.GetArtistByID)
+			artists.GET(":id/albums", controller.GetArtistAlbums)
+			artists.GET("", controller.GetArtists)
+			artists.POST("", controller.PostArtist)
+			artists.PATCH(":id", controller.UpdateArtist)
+			artists.DELETE(":id", controller.DeleteArtistByID)
 		}
 	}
 
+	// v2 shows prices as exact money and converts them to other currencies
+	v2 := router.Group("/api/v2")
+	{
+		v2.GET("/albums", controller.GetAlbumsV2)
+		v2.GET("/albums/:id", controller.GetAlbumByIDV2)
+
+		v2.GET("/exchange-rates", controller.GetExchangeRates)
+		v2.PUT("/exchange-rates", controller.PutExchangeRates)
+	}
+
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return router
 }
+
+// customMethods dispatches "collection:method" style routes. gin can't register
+// a literal colon, so the method name is captured as a parameter instead.
+func customMethods(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler, ok := handlers[c.Param("method")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "method not found"})
+			return
+		}
+
+		handler(c)
+	}
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Terms splits text into lower case words
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), notWordRune)
+}
+
+// Grams returns the distinct trigrams of the words of text, every word padded
+// with two spaces in front and one behind so words sharing their first
+// letters share grams too. A typo only breaks the grams around it, the fuzzy
+// search looks for candidates among the albums sharing grams with the terms.
+func Grams(text string) []string {
+	seen := map[string]bool{}
+	grams := []string{}
+
+	for _, word := range Terms(text) {
+		runes := []rune("  " + word + " ")
+
+		for i := 0; i+3 <= len(runes); i++ {
+			if gram := string(runes[i : i+3]); !seen[gram] {
+				seen[gram] = true
+				grams = append(grams, gram)
+			}
+		}
+	}
+
+	return grams
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// Distance is the Levenshtein distance between a and b
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// MaxEdits is the number of typos tolerated in a query term of this length
+func MaxEdits(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	}
+
+	return 2
+}
+
+// Similarity rates how well word matches a query term, from 0 (no match)
+// to 1 (same word). Prefixes count, so "beat" matches "beatles".
+func Similarity(term, word string) float64 {
+	if term == word {
+		return 1
+	}
+
+	n := utf8.RuneCountInString(term)
+
+	if n >= 2 && strings.HasPrefix(word, term) {
+		return 0.9
+	}
+
+	maxEdits := MaxEdits(term)
+	if maxEdits == 0 {
+		return 0
+	}
+
+	if d := Distance(term, word); d <= maxEdits {
+		return 1 - float64(d)/float64(n+1)
+	}
+
+	// a typo in a prefix, "beatl" for "beatles"
+	if runes := []rune(word); len(runes) > n {
+		if d := Distance(term, string(runes[:n])); d <= maxEdits {
+			return 0.8 * (1 - float64(d)/float64(n+1))
+		}
+	}
+
+	return 0
+}
+
+// Score rates how well text matches the query terms, the average of the best
+// match of every term. It is 0 when none of them matches.
+func Score(terms []string, text string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	words := Terms(text)
+	total := 0.0
+
+	for _, term := range terms {
+		best := 0.0
+
+		for _, word := range words {
+			if s := Similarity(term, word); s > best {
+				best = s
+			}
+		}
+
+		total += best
+	}
+
+	return total / float64(len(terms))
+}
+
+// Highlight wraps the words of text that match one of the terms in <em>
+// tags. Everything else is HTML escaped, so the result can be shown as is.
+func Highlight(text string, terms []string) string {
+	var b strings.Builder
+
+	for len(text) > 0 {
+		i := strings.IndexFunc(text, func(r rune) bool { return !notWordRune(r) })
+		if i < 0 {
+			i = len(text)
+		}
+
+		b.WriteString(html.EscapeString(text[:i]))
+		text = text[i:]
+
+		j := strings.IndexFunc(text, notWordRune)
+		if j < 0 {
+			j = len(text)
+		}
+
+		word := text[:j]
+		text = text[j:]
+
+		if word == "" {
+			continue
+		}
+
+		if matches(strings.ToLower(word), terms) {
+			b.WriteString("<em>" + html.EscapeString(word) + "</em>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+	}
+
+	return b.String()
+}
+
+func matches(word string, terms []string) bool {
+	for _, term := range terms {
+		if Similarity(term, word) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
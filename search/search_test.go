@@ -0,0 +1,74 @@
+package search_test
+
+import (
+	"rest/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+
+	test_cases := []struct {
+		a, b     string
+		distance int
+	}{
+		{a: "", b: "", distance: 0},
+		{a: "beatles", b: "beatles", distance: 0},
+		{a: "beatels", b: "beatles", distance: 2},
+		{a: "coltrane", b: "coltran", distance: 1},
+		{a: "björk", b: "bjork", distance: 1},
+		{a: "", b: "jeru", distance: 4},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			assert.Equal(t, tc.distance, search.Distance(tc.a, tc.b))
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+
+	test_cases := []struct {
+		name  string
+		query string
+		text  string
+		match bool
+	}{
+		{name: "exact word", query: "train", text: "Blue Train John Coltrane", match: true},
+		{name: "prefix", query: "colt", text: "Blue Train John Coltrane", match: true},
+		{name: "typo", query: "coltrain", text: "Blue Train John Coltrane", match: true},
+		{name: "typo in a prefix", query: "beatl", text: "Abbey Road The Beatles", match: true},
+		{name: "short words must be exact", query: "jaz", text: "Jeru Gerry Mulligan", match: false},
+		{name: "unrelated", query: "mozart", text: "Blue Train John Coltrane", match: false},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			score := search.Score(search.Terms(tc.query), tc.text)
+			assert.Equal(t, tc.match, score > 0, "score %v", score)
+		})
+	}
+
+	exact := search.Score([]string{"coltrane"}, "John Coltrane")
+	fuzzy := search.Score([]string{"coltrain"}, "John Coltrane")
+	assert.Greater(t, exact, fuzzy)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t,
+		"<em>Blue</em> Train &amp; <em>Coltrane</em>&#39;s",
+		search.Highlight("Blue Train & Coltrane's", []string{"blu", "coltrain"}))
+	assert.Equal(t, "Jeru", search.Highlight("Jeru", []string{"train"}))
+}
+
+func TestGrams(t *testing.T) {
+	assert.Equal(t, []string{"  b", " bl", "blu", "lue", "ue "}, search.Grams("Blue"))
+	assert.Equal(t, []string{"  a", " a "}, search.Grams("a A"))
+	assert.Empty(t, search.Grams(" - "))
+
+	// a typo leaves the grams away from it
+	assert.Subset(t, search.Grams("train"), []string{"  t", " tr"})
+	assert.Subset(t, search.Grams("trian"), []string{"  t", " tr"})
+}
@@ -0,0 +1,162 @@
+package shipping
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"rest/models"
+	"strings"
+)
+
+var ErrNoShipping = errors.New("no shipping to this country")
+
+// the weight in grams of the albums of each format, albums with a weight of
+// their own don't use it
+var DefaultFormatWeights = map[string]int{"cd": 110, "vinyl": 320, "digital": 0}
+
+// Parcel is what an order ships
+type Parcel struct {
+	// grams
+	Weight int
+	// what the albums cost, in the currency of the calculator
+	Value *big.Rat
+}
+
+// Calculator prices the shipping of parcels
+type Calculator interface {
+	// Cost is the shipping of the parcel to the country, in Currency
+	Cost(country string, parcel Parcel) (*big.Rat, error)
+	// Currency is the currency of the costs and of the parcel values, empty
+	// when any currency goes
+	Currency() string
+	// Weight is the weight in grams of an album of the format
+	Weight(format string) int
+}
+
+// Table is a Calculator reading its prices from zones of countries, each
+// with prices by weight and a value above which shipping is free
+type Table struct {
+	currency string
+	weights  map[string]int
+	zones    []zone
+}
+
+type zone struct {
+	// ISO 3166-1 alpha-2 codes, * for every other country
+	Countries []string `json:"countries"`
+	// by increasing max weight, the last one may have no max weight
+	Rates []struct {
+		MaxWeight int            `json:"max_weight"`
+		Price     models.Decimal `json:"price"`
+	} `json:"rates"`
+	FreeOver *models.Decimal `json:"free_over"`
+}
+
+// ReadTable reads a table written as
+//
+//	{"currency": "USD", "format_weights": {"vinyl": 300},
+//	 "zones": [{"countries": ["US"], "free_over": "50",
+//	            "rates": [{"max_weight": 500, "price": "4.99"}, {"price": "9.99"}]}]}
+func ReadTable(r io.Reader) (*Table, error) {
+	var file struct {
+		Currency      string         `json:"currency"`
+		FormatWeights map[string]int `json:"format_weights"`
+		Zones         []zone         `json:"zones"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	if len(file.Currency) != 3 {
+		return nil, errors.New("invalid currency " + file.Currency)
+	}
+
+	table := &Table{currency: strings.ToUpper(file.Currency), weights: map[string]int{}, zones: file.Zones}
+
+	for format, weight := range DefaultFormatWeights {
+		table.weights[format] = weight
+	}
+
+	for format, weight := range file.FormatWeights {
+		table.weights[format] = weight
+	}
+
+	for _, z := range table.zones {
+		if len(z.Rates) == 0 {
+			return nil, errors.New("a zone has no rates")
+		}
+	}
+
+	return table, nil
+}
+
+func (t *Table) Currency() string {
+	return t.currency
+}
+
+func (t *Table) Weight(format string) int {
+	return t.weights[format]
+}
+
+func (t *Table) Cost(country string, parcel Parcel) (*big.Rat, error) {
+	if parcel.Weight <= 0 {
+		return new(big.Rat), nil
+	}
+
+	z, ok := t.zone(strings.ToUpper(country))
+
+	if !ok {
+		return nil, ErrNoShipping
+	}
+
+	if z.FreeOver != nil && parcel.Value != nil && parcel.Value.Cmp(z.FreeOver.Rat()) >= 0 {
+		return new(big.Rat), nil
+	}
+
+	for _, rate := range z.Rates {
+		if rate.MaxWeight == 0 || parcel.Weight <= rate.MaxWeight {
+			return rate.Price.Rat(), nil
+		}
+	}
+
+	return nil, errors.New("the parcel is too heavy to ship")
+}
+
+func (t *Table) zone(country string) (zone, bool) {
+	var fallback *zone
+
+	for i, z := range t.zones {
+		for _, c := range z.Countries {
+			if strings.ToUpper(c) == country {
+				return z, true
+			}
+
+			if c == "*" && fallback == nil {
+				fallback = &t.zones[i]
+			}
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+
+	return zone{}, false
+}
+
+// Free is a Calculator for stores that don't charge shipping
+type Free struct{}
+
+func (f Free) Cost(country string, parcel Parcel) (*big.Rat, error) {
+	return new(big.Rat), nil
+}
+
+func (f Free) Currency() string {
+	return ""
+}
+
+func (f Free) Weight(format string) int {
+	return DefaultFormatWeights[format]
+}
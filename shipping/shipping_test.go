@@ -0,0 +1,72 @@
+package shipping_test
+
+import (
+	"math/big"
+	"rest/shipping"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const table = `{
+	"currency": "usd",
+	"format_weights": {"vinyl": 300},
+	"zones": [
+		{"countries": ["US"], "free_over": "50", "rates": [{"max_weight": 500, "price": "4.99"}, {"price": "9.99"}]},
+		{"countries": ["*"], "rates": [{"max_weight": 1000, "price": "14.99"}]}
+	]
+}`
+
+func TestCost(t *testing.T) {
+
+	calculator, err := shipping.ReadTable(strings.NewReader(table))
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	test_cases := []struct {
+		name    string
+		country string
+		parcel  shipping.Parcel
+		cost    string
+		err     bool
+	}{
+		{name: "light", country: "US", parcel: shipping.Parcel{Weight: 300, Value: big.NewRat(20, 1)}, cost: "4.99"},
+		{name: "heavy", country: "us", parcel: shipping.Parcel{Weight: 900, Value: big.NewRat(20, 1)}, cost: "9.99"},
+		{name: "free over the threshold", country: "US", parcel: shipping.Parcel{Weight: 900, Value: big.NewRat(50, 1)}, cost: "0.00"},
+		{name: "nothing to ship", country: "US", parcel: shipping.Parcel{Value: big.NewRat(20, 1)}, cost: "0.00"},
+		{name: "every other country", country: "FR", parcel: shipping.Parcel{Weight: 300, Value: big.NewRat(80, 1)}, cost: "14.99"},
+		{name: "too heavy", country: "FR", parcel: shipping.Parcel{Weight: 3000, Value: big.NewRat(20, 1)}, err: true},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cost, err := calculator.Cost(tc.country, tc.parcel)
+
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.cost, cost.FloatString(2))
+			}
+		})
+	}
+
+	assert.Equal(t, "USD", calculator.Currency())
+	assert.Equal(t, 300, calculator.Weight("vinyl"))
+	assert.Equal(t, 110, calculator.Weight("cd"))
+	assert.Equal(t, 0, calculator.Weight("digital"))
+}
+
+func TestNoShipping(t *testing.T) {
+
+	calculator, _ := shipping.ReadTable(strings.NewReader(`{"currency": "EUR", "zones": [{"countries": ["DE"], "rates": [{"price": "5"}]}]}`))
+
+	_, err := calculator.Cost("US", shipping.Parcel{Weight: 100})
+
+	assert.Equal(t, shipping.ErrNoShipping, err)
+}
@@ -0,0 +1,64 @@
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid signature")
+	ErrExpired = errors.New("signature expired")
+)
+
+// Signer signs URL paths so they can be handed out and checked later
+// without storing anything. A signed URL only works for its own path and
+// until it expires.
+type Signer struct {
+	key []byte
+}
+
+func New(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Sign returns the query parameters that make path valid until expires
+func (s *Signer) Sign(path string, expires time.Time) url.Values {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	return url.Values{
+		"expires":   {exp},
+		"signature": {s.signature(path, exp)},
+	}
+}
+
+// Verify checks the expires and signature parameters of a request for path
+func (s *Signer) Verify(path string, query url.Values, now time.Time) error {
+	exp := query.Get("expires")
+	expires, err := strconv.ParseInt(exp, 10, 64)
+
+	if err != nil {
+		return ErrInvalid
+	}
+
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(s.signature(path, exp))) {
+		return ErrInvalid
+	}
+
+	if now.Unix() > expires {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func (s *Signer) signature(path, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(path + "\n" + expires))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
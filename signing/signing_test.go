@@ -0,0 +1,43 @@
+package signing_test
+
+import (
+	"net/url"
+	"rest/signing"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+
+	signer := signing.New([]byte("secret"))
+	now := time.Unix(1700000000, 0)
+	path := "/api/v1/albums/1/tracks/2/preview"
+	signed := signer.Sign(path, now.Add(time.Minute))
+
+	tampered := url.Values{"expires": {"1800000000"}, "signature": signed["signature"]}
+
+	test_cases := []struct {
+		name   string
+		signer *signing.Signer
+		path   string
+		query  url.Values
+		now    time.Time
+		err    error
+	}{
+		{name: "valid", signer: signer, path: path, query: signed, now: now},
+		{name: "valid until the last second", signer: signer, path: path, query: signed, now: now.Add(time.Minute)},
+		{name: "expired", signer: signer, path: path, query: signed, now: now.Add(time.Minute + time.Second), err: signing.ErrExpired},
+		{name: "another path", signer: signer, path: "/api/v1/albums/1/tracks/3/preview", query: signed, now: now, err: signing.ErrInvalid},
+		{name: "extended expiry", signer: signer, path: path, query: tampered, now: now, err: signing.ErrInvalid},
+		{name: "another key", signer: signing.New([]byte("other")), path: path, query: signed, now: now, err: signing.ErrInvalid},
+		{name: "unsigned", signer: signer, path: path, query: url.Values{}, now: now, err: signing.ErrInvalid},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.err, tc.signer.Verify(tc.path, tc.query, tc.now))
+		})
+	}
+}
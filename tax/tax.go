@@ -0,0 +1,87 @@
+package tax
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"rest/models"
+	"strings"
+)
+
+// Rate is the tax of a place
+type Rate struct {
+	Name    string
+	Percent *big.Rat
+	// whether shipping is taxed too
+	Shipping bool
+}
+
+// Calculator finds the tax rate of the places orders ship to
+type Calculator interface {
+	Rate(country, region string) Rate
+}
+
+// Table is a Calculator reading its rates from a table. A region without a
+// rate of its own takes the rate of its country, and places the table
+// doesn't know aren't taxed.
+type Table struct {
+	rates map[string]Rate
+}
+
+type tableEntry struct {
+	// ISO 3166-1 alpha-2
+	Country string `json:"country"`
+	// ISO 3166-2 subdivision code without the country, e.g. CA, empty for
+	// the whole country
+	Region   string         `json:"region"`
+	Name     string         `json:"name"`
+	Percent  models.Decimal `json:"percent"`
+	Shipping bool           `json:"shipping"`
+}
+
+// ReadTable reads a table written as
+//
+//	{"rates": [{"country": "US", "region": "CA", "name": "Sales tax", "percent": "7.25"}]}
+func ReadTable(r io.Reader) (*Table, error) {
+	var file struct {
+		Rates []tableEntry `json:"rates"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	table := &Table{rates: map[string]Rate{}}
+
+	for _, entry := range file.Rates {
+		if len(entry.Country) != 2 {
+			return nil, errors.New("invalid country " + entry.Country)
+		}
+
+		percent := entry.Percent.Rat()
+		if percent == nil || percent.Sign() < 0 || percent.Cmp(big.NewRat(100, 1)) > 0 {
+			return nil, errors.New("invalid percent for " + entry.Country)
+		}
+
+		table.rates[key(entry.Country, entry.Region)] = Rate{Name: entry.Name, Percent: percent, Shipping: entry.Shipping}
+	}
+
+	return table, nil
+}
+
+func (t *Table) Rate(country, region string) Rate {
+	if rate, ok := t.rates[key(country, region)]; ok && region != "" {
+		return rate
+	}
+
+	if rate, ok := t.rates[key(country, "")]; ok {
+		return rate
+	}
+
+	return Rate{Percent: new(big.Rat)}
+}
+
+func key(country, region string) string {
+	return strings.ToUpper(country) + "-" + strings.ToUpper(region)
+}
@@ -0,0 +1,51 @@
+package tax_test
+
+import (
+	"rest/tax"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRate(t *testing.T) {
+
+	calculator, err := tax.ReadTable(strings.NewReader(`{"rates": [
+		{"country": "US", "percent": "0"},
+		{"country": "US", "region": "CA", "name": "Sales tax", "percent": "7.25"},
+		{"country": "DE", "name": "VAT", "percent": "19", "shipping": true}
+	]}`))
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	test_cases := []struct {
+		name     string
+		country  string
+		region   string
+		percent  string
+		shipping bool
+	}{
+		{name: "region", country: "US", region: "CA", percent: "7.25"},
+		{name: "region without a rate of its own", country: "US", region: "OR", percent: "0.00"},
+		{name: "country", country: "de", percent: "19.00", shipping: true},
+		{name: "unknown country", country: "FR", percent: "0.00"},
+	}
+
+	for _, tc := range test_cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate := calculator.Rate(tc.country, tc.region)
+
+			assert.Equal(t, tc.percent, rate.Percent.FloatString(2))
+			assert.Equal(t, tc.shipping, rate.Shipping)
+		})
+	}
+}
+
+func TestInvalidTable(t *testing.T) {
+
+	_, err := tax.ReadTable(strings.NewReader(`{"rates": [{"country": "US", "percent": "120"}]}`))
+
+	assert.Error(t, err)
+}